@@ -105,6 +105,39 @@ type OperatorSpec struct {
 	// set by external tools to store and retrieve arbitrary metadata. They are not
 	// queryable and should be preserved when modifying objects.
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Optional: Image registry mirrors, rewriting the registry prefix of every operand image
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Image Registry Mirrors"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	ImageRegistryMirrors []ImageRegistryMirror `json:"imageRegistryMirrors,omitempty"`
+
+	// Optional: Resolve operand image tags to digests through the registry once per ClusterPolicy generation
+	// and deploy the images by digest, so that all nodes run exactly the same image
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Pin image digests"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	PinImageDigests *bool `json:"pinImageDigests,omitempty"`
+
+	// Optional: Pause reconciliation of all states, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// ImageRegistryMirror describes a rule rewriting images starting with Source to start with Mirror instead
+type ImageRegistryMirror struct {
+	// Source prefix of the image, e.g. a registry "nvcr.io" or a repository "nvcr.io/nvidia"
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// Mirror prefix replacing the source prefix, e.g. "registry.example.com/nvcr.io"
+	// +kubebuilder:validation:MinLength=1
+	Mirror string `json:"mirror"`
 }
 
 // EnvVar represents an environment variable present in a Container.
@@ -114,6 +147,10 @@ type EnvVar struct {
 
 	// Value of the environment variable.
 	Value string `json:"value,omitempty"`
+
+	// Source for the environment variable's value. Cannot be used if value is not empty.
+	// +kubebuilder:validation:Optional
+	ValueFrom *corev1.EnvVarSource `json:"valueFrom,omitempty"`
 }
 
 // ResourceRequirements describes the compute resource requirements.
@@ -178,6 +215,49 @@ type DaemonsetsSpec struct {
 	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
 }
 
+// SchedulingSpec describes per-component scheduling configuration of a Daemonset.
+// Fields set here take precedence over the common DaemonsetsSpec configuration.
+type SchedulingSpec struct {
+	// Optional: Node selector labels, merged with the node selector of the Daemonset
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Node Selector"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:text"
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Optional: Set affinity, replaces the affinity of the Daemonset
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Affinity"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:nodeAffinity"
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Optional: Set tolerations, added to the common Daemonsets tolerations
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Tolerations"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:io.kubernetes:Tolerations"
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Optional: PriorityClassName, overrides the common Daemonsets priorityClassName
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="PriorityClassName"
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Optional: UpdateStrategy, overrides the common Daemonsets updateStrategy
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="UpdateStrategy"
+	UpdateStrategy string `json:"updateStrategy,omitempty"`
+
+	// Optional: Configuration for rolling update, overrides the common Daemonsets rollingUpdate
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Rolling update configuration"
+	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
+}
+
 // InitContainerSpec describes configuration for initContainer image used with all components
 type InitContainerSpec struct {
 	// Repository represents image repository path
@@ -256,6 +336,16 @@ type ValidatorSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Environment Variables"
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:text"
 	Env []EnvVar `json:"env,omitempty"`
+
+	// Optional: Pause reconciliation of the operator-validation state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // PluginValidatorSpec defines validator spec for NVIDIA Device Plugin
@@ -443,6 +533,16 @@ type DriverSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Kernel module configuration parameters for the NVIDIA driver"
 	KernelModuleConfig *KernelModuleConfigSpec `json:"kernelModuleConfig,omitempty"`
+
+	// Optional: Pause reconciliation of the driver state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // ToolkitSpec defines the properties for NVIDIA Container Toolkit deployment
@@ -504,6 +604,16 @@ type ToolkitSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Toolkit install directory on the host"
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
 	InstallDir string `json:"installDir,omitempty"`
+
+	// Optional: Pause reconciliation of the container-toolkit state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // DevicePluginSpec defines the properties for NVIDIA Device Plugin deployment
@@ -562,6 +672,16 @@ type DevicePluginSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Configuration for the NVIDIA Device Plugin via the ConfigMap"
 	Config *DevicePluginConfig `json:"config,omitempty"`
+
+	// Optional: Pause reconciliation of the device-plugin state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // DevicePluginConfig defines ConfigMap name for NVIDIA Device Plugin config
@@ -631,6 +751,16 @@ type NodeStatusExporterSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Environment Variables"
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:text"
 	Env []EnvVar `json:"env,omitempty"`
+
+	// Optional: Pause reconciliation of the node-status-exporter state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // DriverRepoConfigSpec defines custom repo configuration for NVIDIA Driver container
@@ -745,6 +875,16 @@ type GPUFeatureDiscoverySpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Environment Variables"
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:text"
 	Env []EnvVar `json:"env,omitempty"`
+
+	// Optional: Pause reconciliation of the gpu-feature-discovery state, resources are neither created, updated nor deleted while paused
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Paused"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	Paused *bool `json:"paused,omitempty"`
+
+	// Optional: Scheduling configuration of the component Daemonset, applied on top of the common Daemonsets configuration
+	SchedulingSpec `json:",inline"`
 }
 
 // GPUDirectRDMASpec defines the properties for nvidia-peermem deployment
@@ -839,6 +979,10 @@ const (
 	NotReady State = "notReady"
 	// Disabled indicates if the state is disabled
 	Disabled State = "disabled"
+	// Paused indicates reconciliation of the state is paused
+	Paused State = "paused"
+	// Skipped indicates the state was not reconciled as a state it depends on is not ready
+	Skipped State = "skipped"
 )
 
 // ClusterPolicyStatus defines the observed state of ClusterPolicy
@@ -848,6 +992,63 @@ type ClusterPolicyStatus struct {
 	State State `json:"state"`
 	// Namespace indicates a namespace in which the operator is installed
 	Namespace string `json:"namespace,omitempty"`
+	// Conditions is a list of conditions representing the ClusterPolicy's current state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// States reports the result of the last reconciliation of every operand state
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	States []StateStatus `json:"states,omitempty"`
+	// ImageDigests lists the digests operand images were pinned to when spec.operator.pinImageDigests is enabled
+	// +optional
+	// +listType=map
+	// +listMapKey=image
+	ImageDigests []ImageDigestStatus `json:"imageDigests,omitempty"`
+	// ImageDigestsGeneration is the ClusterPolicy generation ImageDigests were resolved for
+	// +optional
+	ImageDigestsGeneration int64 `json:"imageDigestsGeneration,omitempty"`
+}
+
+// ImageDigestStatus records the digest an operand image reference was resolved to
+type ImageDigestStatus struct {
+	// Image reference as configured, e.g. "nvcr.io/nvidia/cuda:12.2.0-base-ubi8"
+	Image string `json:"image"`
+	// Digest the image reference was resolved to, e.g. "sha256:..."
+	Digest string `json:"digest"`
+}
+
+// Condition types reported in ClusterPolicyStatus.Conditions
+const (
+	// ConditionReady indicates all enabled states of the ClusterPolicy are ready
+	ConditionReady = "Ready"
+	// ConditionProgressing indicates at least one state is still rolling out
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded indicates reconciliation of a state failed with an error
+	ConditionDegraded = "Degraded"
+)
+
+// StateStatus defines the observed state of a single operand state (e.g. state-device-plugin)
+type StateStatus struct {
+	// Name of the state, as defined by its assets directory
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=ready;notReady;disabled;paused;skipped
+	// State indicates status of the state
+	State State `json:"state"`
+	// Reason is a CamelCase reason for the current state
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the current state
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the state changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// ObservedGeneration is the ClusterPolicy generation the state was computed from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -884,6 +1085,32 @@ func (p *ClusterPolicy) SetStatus(s State, ns string) {
 	p.Status.Namespace = ns
 }
 
+// SetStateStatus adds or updates the status entry of a single state.
+// LastTransitionTime is only bumped when the state value changes.
+func (p *ClusterPolicy) SetStateStatus(ss StateStatus) {
+	for i := range p.Status.States {
+		existing := &p.Status.States[i]
+		if existing.Name != ss.Name {
+			continue
+		}
+		if existing.State != ss.State || existing.LastTransitionTime.IsZero() {
+			existing.State = ss.State
+			existing.LastTransitionTime = ss.LastTransitionTime
+			if existing.LastTransitionTime.IsZero() {
+				existing.LastTransitionTime = metav1.Now()
+			}
+		}
+		existing.Reason = ss.Reason
+		existing.Message = ss.Message
+		existing.ObservedGeneration = ss.ObservedGeneration
+		return
+	}
+	if ss.LastTransitionTime.IsZero() {
+		ss.LastTransitionTime = metav1.Now()
+	}
+	p.Status.States = append(p.Status.States, ss)
+}
+
 func imagePath(repository string, image string, version string, imagePathEnvName string) (string, error) {
 	// ImagePath is obtained using following priority
 	// 1. ClusterPolicy (i.e through repository/image/path variables in CRD)
@@ -915,36 +1142,62 @@ func imagePath(repository string, image string, version string, imagePathEnvName
 	return "", fmt.Errorf("Empty image path provided through both ClusterPolicy CR and ENV %s", imagePathEnvName)
 }
 
-// ImagePath sets image path for given component type
-func ImagePath(spec interface{}) (string, error) {
+// ImagePath sets image path for given component type, rewritten according to the given registry mirrors
+func ImagePath(spec interface{}, mirrors []ImageRegistryMirror) (string, error) {
+	var path string
+	var err error
 	switch v := spec.(type) {
 	case *DriverSpec:
 		config := spec.(*DriverSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "DRIVER_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "DRIVER_IMAGE")
 	case *ToolkitSpec:
 		config := spec.(*ToolkitSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "CONTAINER_TOOLKIT_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "CONTAINER_TOOLKIT_IMAGE")
 	case *DevicePluginSpec:
 		config := spec.(*DevicePluginSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "DEVICE_PLUGIN_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "DEVICE_PLUGIN_IMAGE")
 	case *NodeStatusExporterSpec:
 		config := spec.(*NodeStatusExporterSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "VALIDATOR_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "VALIDATOR_IMAGE")
 	case *GPUFeatureDiscoverySpec:
 		config := spec.(*GPUFeatureDiscoverySpec)
-		return imagePath(config.Repository, config.Image, config.Version, "GFD_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "GFD_IMAGE")
 	case *ValidatorSpec:
 		config := spec.(*ValidatorSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "VALIDATOR_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "VALIDATOR_IMAGE")
 	case *InitContainerSpec:
 		config := spec.(*InitContainerSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "CUDA_BASE_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "CUDA_BASE_IMAGE")
 	case *DriverManagerSpec:
 		config := spec.(*DriverManagerSpec)
-		return imagePath(config.Repository, config.Image, config.Version, "DRIVER_MANAGER_IMAGE")
+		path, err = imagePath(config.Repository, config.Image, config.Version, "DRIVER_MANAGER_IMAGE")
 	default:
 		return "", fmt.Errorf("Invalid type to construct image path: %v", v)
 	}
+	if err != nil {
+		return "", err
+	}
+	return MirrorImage(path, mirrors), nil
+}
+
+// MirrorImage rewrites image according to the registry mirror whose source is
+// the longest prefix of the image. A source only matches on a path component,
+// tag or digest boundary, so "nvcr.io/nvidia" does not match "nvcr.io/nvidia-test/image"
+func MirrorImage(image string, mirrors []ImageRegistryMirror) string {
+	mirrored := image
+	matchLen := 0
+	for _, m := range mirrors {
+		source := strings.TrimSuffix(m.Source, "/")
+		if source == "" || len(source) <= matchLen || !strings.HasPrefix(image, source) {
+			continue
+		}
+		if rest := image[len(source):]; rest != "" && !strings.ContainsAny(rest[:1], "/:@") {
+			continue
+		}
+		mirrored = strings.TrimSuffix(m.Mirror, "/") + image[len(source):]
+		matchLen = len(source)
+	}
+	return mirrored
 }
 
 // ImagePullPolicy sets image pull policy
@@ -1045,6 +1298,16 @@ func (g *GPUDirectRDMASpec) IsEnabled() bool {
 	return *g.Enabled
 }
 
+// IsHostMOFED returns true if the MOFED drivers pre-installed on the host
+// are used for GPUDirect RDMA
+func (g *GPUDirectRDMASpec) IsHostMOFED() bool {
+	if g.UseHostMOFED == nil {
+		// default is false if not specified by user
+		return false
+	}
+	return *g.UseHostMOFED
+}
+
 // IsNLSEnabled returns true if NLS should be used for licensing the driver
 func (l *DriverLicensingConfigSpec) IsNLSEnabled() bool {
 	if l.NLSEnabled == nil {
@@ -1063,6 +1326,78 @@ func (c *CDIConfigSpec) IsEnabled() bool {
 	return *c.Enabled
 }
 
+// IsPinImageDigestsEnabled returns true if operand image tags are to be resolved and pinned to digests
+func (o *OperatorSpec) IsPinImageDigestsEnabled() bool {
+	if o.PinImageDigests == nil {
+		// pinning image digests is disabled by default
+		return false
+	}
+	return *o.PinImageDigests
+}
+
+// IsPaused returns true if reconciliation of all states is paused
+func (o *OperatorSpec) IsPaused() bool {
+	if o.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *o.Paused
+}
+
+// IsPaused returns true if reconciliation of the driver state is paused
+func (d *DriverSpec) IsPaused() bool {
+	if d.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *d.Paused
+}
+
+// IsPaused returns true if reconciliation of the container-toolkit state is paused
+func (t *ToolkitSpec) IsPaused() bool {
+	if t.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *t.Paused
+}
+
+// IsPaused returns true if reconciliation of the device-plugin state is paused
+func (p *DevicePluginSpec) IsPaused() bool {
+	if p.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *p.Paused
+}
+
+// IsPaused returns true if reconciliation of the node-status-exporter state is paused
+func (e *NodeStatusExporterSpec) IsPaused() bool {
+	if e.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *e.Paused
+}
+
+// IsPaused returns true if reconciliation of the gpu-feature-discovery state is paused
+func (g *GPUFeatureDiscoverySpec) IsPaused() bool {
+	if g.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *g.Paused
+}
+
+// IsPaused returns true if reconciliation of the operator-validation state is paused
+func (v *ValidatorSpec) IsPaused() bool {
+	if v.Paused == nil {
+		// reconciliation is not paused by default
+		return false
+	}
+	return *v.Paused
+}
+
 // IsDefault returns true if CDI is enabled as the default
 // mechanism for providing GPU access to containers
 func (c *CDIConfigSpec) IsDefault() bool {
@@ -0,0 +1,378 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var validImagePullPolicies = []string{"Always", "Never", "IfNotPresent"}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks for ClusterPolicy
+func (p *ClusterPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(p).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-xdxct-com-v1-clusterpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=xdxct.com,resources=clusterpolicies,verbs=create;update,versions=v1,name=mclusterpolicy.xdxct.com,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ClusterPolicy{}
+
+// Default implements webhook.Defaulter. Optional boolean fields are set
+// explicitly to the value their IsEnabled() and IsPaused() helpers assume
+// when unset, so that the stored object reflects what the operator will deploy.
+func (p *ClusterPolicy) Default() {
+	spec := &p.Spec
+
+	spec.Operator.PinImageDigests = newBoolPtr(spec.Operator.IsPinImageDigestsEnabled())
+	spec.Operator.Paused = newBoolPtr(spec.Operator.IsPaused())
+	spec.Driver.Enabled = newBoolPtr(spec.Driver.IsEnabled())
+	spec.Driver.UsePrecompiled = newBoolPtr(spec.Driver.UsePrecompiledDrivers())
+	spec.Driver.Paused = newBoolPtr(spec.Driver.IsPaused())
+	if spec.Driver.GPUDirectRDMA != nil {
+		spec.Driver.GPUDirectRDMA.Enabled = newBoolPtr(spec.Driver.GPUDirectRDMA.IsEnabled())
+		spec.Driver.GPUDirectRDMA.UseHostMOFED = newBoolPtr(spec.Driver.GPUDirectRDMA.IsHostMOFED())
+	}
+	if spec.Driver.LicensingConfig != nil {
+		spec.Driver.LicensingConfig.NLSEnabled = newBoolPtr(spec.Driver.LicensingConfig.IsNLSEnabled())
+	}
+	spec.Toolkit.Enabled = newBoolPtr(spec.Toolkit.IsEnabled())
+	spec.Toolkit.Paused = newBoolPtr(spec.Toolkit.IsPaused())
+	spec.DevicePlugin.Enabled = newBoolPtr(spec.DevicePlugin.IsEnabled())
+	spec.DevicePlugin.Paused = newBoolPtr(spec.DevicePlugin.IsPaused())
+	spec.GPUFeatureDiscovery.Enabled = newBoolPtr(spec.GPUFeatureDiscovery.IsEnabled())
+	spec.GPUFeatureDiscovery.Paused = newBoolPtr(spec.GPUFeatureDiscovery.IsPaused())
+	spec.NodeStatusExporter.Enabled = newBoolPtr(spec.NodeStatusExporter.IsEnabled())
+	spec.NodeStatusExporter.Paused = newBoolPtr(spec.NodeStatusExporter.IsPaused())
+	spec.PSP.Enabled = newBoolPtr(spec.PSP.IsEnabled())
+	spec.PSA.Enabled = newBoolPtr(spec.PSA.IsEnabled())
+	spec.CDI.Enabled = newBoolPtr(spec.CDI.IsEnabled())
+	spec.CDI.Default = newBoolPtr(spec.CDI.IsDefault())
+	spec.Validator.Paused = newBoolPtr(spec.Validator.IsPaused())
+}
+
+// +kubebuilder:webhook:path=/validate-xdxct-com-v1-clusterpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=xdxct.com,resources=clusterpolicies,verbs=create;update,versions=v1,name=vclusterpolicy.xdxct.com,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ClusterPolicy{}
+
+// ValidateCreate implements webhook.Validator
+func (p *ClusterPolicy) ValidateCreate() (admission.Warnings, error) {
+	return nil, p.Validate()
+}
+
+// ValidateUpdate implements webhook.Validator. Updates leaving the spec unchanged,
+// such as the removal of the finalizer, are not validated, nor is a ClusterPolicy
+// being deleted, so that a ClusterPolicy which does not pass the validation, created
+// before the webhook or before a newer rule, can still be deleted
+func (p *ClusterPolicy) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	if p.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if oldPolicy, ok := old.(*ClusterPolicy); ok {
+		// the update is defaulted before it is validated, the old object may not be
+		oldPolicy = oldPolicy.DeepCopy()
+		oldPolicy.Default()
+		if reflect.DeepEqual(oldPolicy.Spec, p.Spec) {
+			return nil, nil
+		}
+	}
+	return nil, p.Validate()
+}
+
+// ValidateDelete implements webhook.Validator
+func (p *ClusterPolicy) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the ClusterPolicy spec for malformed or contradictory
+// settings and returns an Invalid API error listing all of them
+func (p *ClusterPolicy) Validate() error {
+	allErrs := p.ValidateSpec()
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ClusterPolicy").GroupKind(), p.Name, allErrs)
+}
+
+// ValidateSpec returns all malformed or contradictory settings of the ClusterPolicy spec
+func (p *ClusterPolicy) ValidateSpec() field.ErrorList {
+	return p.Spec.validate(field.NewPath("spec"))
+}
+
+func (s *ClusterPolicySpec) validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	pullPolicies := []struct {
+		path   *field.Path
+		policy string
+	}{
+		{fldPath.Child("operator", "initContainer", "imagePullPolicy"), s.Operator.InitContainer.ImagePullPolicy},
+		{fldPath.Child("driver", "imagePullPolicy"), s.Driver.ImagePullPolicy},
+		{fldPath.Child("driver", "manager", "imagePullPolicy"), s.Driver.Manager.ImagePullPolicy},
+		{fldPath.Child("toolkit", "imagePullPolicy"), s.Toolkit.ImagePullPolicy},
+		{fldPath.Child("devicePlugin", "imagePullPolicy"), s.DevicePlugin.ImagePullPolicy},
+		{fldPath.Child("nodeStatusExporter", "imagePullPolicy"), s.NodeStatusExporter.ImagePullPolicy},
+		{fldPath.Child("gfd", "imagePullPolicy"), s.GPUFeatureDiscovery.ImagePullPolicy},
+		{fldPath.Child("validator", "imagePullPolicy"), s.Validator.ImagePullPolicy},
+	}
+	for _, pull := range pullPolicies {
+		allErrs = append(allErrs, validateImagePullPolicy(pull.policy, pull.path)...)
+	}
+
+	allErrs = append(allErrs, validateImageRegistryMirrors(s.Operator.ImageRegistryMirrors, fldPath.Child("operator", "imageRegistryMirrors"))...)
+	allErrs = append(allErrs, s.Operator.validateRuntime(fldPath.Child("operator"))...)
+
+	envs := []struct {
+		path *field.Path
+		env  []EnvVar
+	}{
+		{fldPath.Child("driver", "env"), s.Driver.Env},
+		{fldPath.Child("driver", "manager", "env"), s.Driver.Manager.Env},
+		{fldPath.Child("toolkit", "env"), s.Toolkit.Env},
+		{fldPath.Child("devicePlugin", "env"), s.DevicePlugin.Env},
+		{fldPath.Child("nodeStatusExporter", "env"), s.NodeStatusExporter.Env},
+		{fldPath.Child("gfd", "env"), s.GPUFeatureDiscovery.Env},
+		{fldPath.Child("validator", "env"), s.Validator.Env},
+		{fldPath.Child("validator", "plugin", "env"), s.Validator.Plugin.Env},
+		{fldPath.Child("validator", "driver", "env"), s.Validator.Driver.Env},
+		{fldPath.Child("validator", "toolkit", "env"), s.Validator.Toolkit.Env},
+	}
+	for _, e := range envs {
+		allErrs = append(allErrs, validateEnv(e.env, e.path)...)
+	}
+
+	allErrs = append(allErrs, s.Daemonsets.validate(fldPath.Child("daemonsets"))...)
+
+	schedulingSpecs := []struct {
+		path  *field.Path
+		sched *SchedulingSpec
+	}{
+		{fldPath.Child("driver"), &s.Driver.SchedulingSpec},
+		{fldPath.Child("toolkit"), &s.Toolkit.SchedulingSpec},
+		{fldPath.Child("devicePlugin"), &s.DevicePlugin.SchedulingSpec},
+		{fldPath.Child("nodeStatusExporter"), &s.NodeStatusExporter.SchedulingSpec},
+		{fldPath.Child("gfd"), &s.GPUFeatureDiscovery.SchedulingSpec},
+		{fldPath.Child("validator"), &s.Validator.SchedulingSpec},
+	}
+	for _, spec := range schedulingSpecs {
+		allErrs = append(allErrs, validateUpdateStrategy(spec.sched.UpdateStrategy, spec.sched.RollingUpdate, spec.path)...)
+	}
+
+	probes := []struct {
+		path          *field.Path
+		probe         *ContainerProbeSpec
+		singleSuccess bool
+	}{
+		{fldPath.Child("driver", "startupProbe"), s.Driver.StartupProbe, true},
+		{fldPath.Child("driver", "livenessProbe"), s.Driver.LivenessProbe, true},
+		{fldPath.Child("driver", "readinessProbe"), s.Driver.ReadinessProbe, false},
+	}
+	for _, p := range probes {
+		allErrs = append(allErrs, validateProbe(p.probe, p.singleSuccess, p.path)...)
+	}
+
+	if s.CDI.IsDefault() && !s.CDI.IsEnabled() {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cdi", "default"), true,
+			"CDI cannot be the default mechanism for GPU access when cdi.enabled is false"))
+	}
+
+	return allErrs
+}
+
+func (d *DaemonsetsSpec) validate(fldPath *field.Path) field.ErrorList {
+	return validateUpdateStrategy(d.UpdateStrategy, d.RollingUpdate, fldPath)
+}
+
+func validateUpdateStrategy(updateStrategy string, rollingUpdate *RollingUpdateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rollingUpdate == nil {
+		return allErrs
+	}
+
+	if updateStrategy == "OnDelete" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollingUpdate"),
+			"rollingUpdate may not be set when updateStrategy is OnDelete"))
+	}
+
+	maxUnavailable := rollingUpdate.MaxUnavailable
+	if maxUnavailable == "" {
+		return allErrs
+	}
+	if err := validateMaxUnavailable(maxUnavailable); err != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollingUpdate", "maxUnavailable"), maxUnavailable, err))
+	}
+
+	return allErrs
+}
+
+// validateMaxUnavailable returns an error message if value is neither a
+// non-negative integer nor a percentage between 0% and 100%
+func validateMaxUnavailable(value string) string {
+	v := intstr.Parse(value)
+	if v.Type == intstr.Int {
+		if v.IntVal < 0 {
+			return "must be greater than or equal to 0"
+		}
+		return ""
+	}
+
+	if !strings.HasSuffix(value, "%") {
+		return "must be an integer or a percentage (e.g. '1' or '25%')"
+	}
+	percent, err := intstr.GetScaledValueFromIntOrPercent(&v, 100, false)
+	if err != nil {
+		return "must be an integer or a percentage (e.g. '1' or '25%')"
+	}
+	if percent < 0 || percent > 100 {
+		return "must be a percentage between 0% and 100%"
+	}
+	return ""
+}
+
+func (o *OperatorSpec) validateRuntime(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch o.DefaultRuntime {
+	case "", Docker, CRIO, Containerd:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("defaultRuntime"), o.DefaultRuntime,
+			[]string{string(Docker), string(CRIO), string(Containerd)}))
+	}
+
+	// the runtime class is created by the operator and referenced by the operand pods
+	if o.RuntimeClass != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(o.RuntimeClass) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("runtimeClass"), o.RuntimeClass, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateProbe checks the probe settings the kubelet would reject. The success
+// threshold of liveness and startup probes must be 1
+func validateProbe(probe *ContainerProbeSpec, singleSuccess bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if probe == nil {
+		return allErrs
+	}
+
+	values := []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", probe.InitialDelaySeconds},
+		{"timeoutSeconds", probe.TimeoutSeconds},
+		{"periodSeconds", probe.PeriodSeconds},
+		{"successThreshold", probe.SuccessThreshold},
+		{"failureThreshold", probe.FailureThreshold},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(v.name), v.value, "must be greater than or equal to 0"))
+		}
+	}
+
+	if singleSuccess && probe.SuccessThreshold > 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("successThreshold"), probe.SuccessThreshold, "must be 1"))
+	}
+
+	return allErrs
+}
+
+func validateImageRegistryMirrors(mirrors []ImageRegistryMirror, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	sources := map[string]bool{}
+	for i, m := range mirrors {
+		idxPath := fldPath.Index(i)
+		source := strings.TrimSuffix(m.Source, "/")
+		if source == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("source"), ""))
+		} else if sources[source] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("source"), m.Source))
+		}
+		sources[source] = true
+		if strings.TrimSuffix(m.Mirror, "/") == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("mirror"), ""))
+		}
+	}
+	return allErrs
+}
+
+func validateEnv(env []EnvVar, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, e := range env {
+		idxPath := fldPath.Index(i)
+		if e.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		}
+		if e.ValueFrom == nil {
+			continue
+		}
+		if e.Value != "" {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("valueFrom"), "",
+				"may not be specified when `value` is not empty"))
+		}
+		sources := 0
+		if e.ValueFrom.FieldRef != nil {
+			sources++
+		}
+		if e.ValueFrom.ResourceFieldRef != nil {
+			sources++
+		}
+		if e.ValueFrom.ConfigMapKeyRef != nil {
+			sources++
+		}
+		if e.ValueFrom.SecretKeyRef != nil {
+			sources++
+		}
+		if sources != 1 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("valueFrom"), "",
+				"must specify exactly one of: `fieldRef`, `resourceFieldRef`, `configMapKeyRef` or `secretKeyRef`"))
+		}
+	}
+	return allErrs
+}
+
+func validateImagePullPolicy(policy string, fldPath *field.Path) field.ErrorList {
+	if policy == "" {
+		return nil
+	}
+	for _, valid := range validImagePullPolicies {
+		if policy == valid {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(fldPath, policy, validImagePullPolicies)}
+}
+
+func newBoolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	sampleClusterPolicyPath = "../../config/samples/v1_clusterpolicy.yaml"
+	// testFinalizer stands for the finalizer the operator sets on the active ClusterPolicy
+	testFinalizer = "xdxct.com/clusterpolicy-cleanup"
+)
+
+// newSampleClusterPolicy returns the sample ClusterPolicy, as created before the webhook
+func newSampleClusterPolicy(t *testing.T) *ClusterPolicy {
+	contents, err := os.ReadFile(sampleClusterPolicyPath)
+	require.NoError(t, err)
+	cp := &ClusterPolicy{}
+	require.NoError(t, yaml.Unmarshal(contents, cp))
+	return cp
+}
+
+func TestClusterPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		description string
+		update      func(cp *ClusterPolicy)
+		expectError bool
+	}{
+		{
+			"sample policy is valid",
+			func(cp *ClusterPolicy) {},
+			false,
+		},
+		{
+			"unknown image pull policy",
+			func(cp *ClusterPolicy) {
+				cp.Spec.DevicePlugin.ImagePullPolicy = "always"
+			},
+			true,
+		},
+		{
+			"cdi default without cdi enabled",
+			func(cp *ClusterPolicy) {
+				cp.Spec.CDI.Enabled = newBoolPtr(false)
+				cp.Spec.CDI.Default = newBoolPtr(true)
+			},
+			true,
+		},
+		{
+			"percentage maxUnavailable",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Daemonsets.RollingUpdate = &RollingUpdateSpec{MaxUnavailable: "25%"}
+			},
+			false,
+		},
+		{
+			"malformed maxUnavailable",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Daemonsets.RollingUpdate = &RollingUpdateSpec{MaxUnavailable: "one"}
+			},
+			true,
+		},
+		{
+			"rollingUpdate with OnDelete strategy",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Daemonsets.UpdateStrategy = "OnDelete"
+				cp.Spec.Daemonsets.RollingUpdate = &RollingUpdateSpec{MaxUnavailable: "1"}
+			},
+			true,
+		},
+		{
+			"env with value from secret",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Driver.Env = []EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "licensing"}, Key: "token"},
+				}}}
+			},
+			false,
+		},
+		{
+			"env with both value and valueFrom",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Toolkit.Env = []EnvVar{{Name: "NODE_NAME", Value: "node", ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+				}}}
+			},
+			true,
+		},
+		{
+			"env with empty valueFrom",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Validator.Plugin.Env = []EnvVar{{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{}}}
+			},
+			true,
+		},
+		{
+			"duplicate image registry mirror source",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Operator.ImageRegistryMirrors = []ImageRegistryMirror{
+					{Source: "nvcr.io", Mirror: "registry.example.com/a"},
+					{Source: "nvcr.io/", Mirror: "registry.example.com/b"},
+				}
+			},
+			true,
+		},
+		{
+			"component rollingUpdate with OnDelete strategy",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Toolkit.UpdateStrategy = "OnDelete"
+				cp.Spec.Toolkit.RollingUpdate = &RollingUpdateSpec{MaxUnavailable: "1"}
+			},
+			true,
+		},
+		{
+			"malformed component maxUnavailable",
+			func(cp *ClusterPolicy) {
+				cp.Spec.GPUFeatureDiscovery.RollingUpdate = &RollingUpdateSpec{MaxUnavailable: "200%"}
+			},
+			true,
+		},
+		{
+			"unknown default runtime",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Operator.DefaultRuntime = "podman"
+			},
+			true,
+		},
+		{
+			"malformed runtime class",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Operator.RuntimeClass = "Nvidia_Runtime"
+			},
+			true,
+		},
+		{
+			"readiness probe with success threshold",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Driver.ReadinessProbe = &ContainerProbeSpec{PeriodSeconds: 10, SuccessThreshold: 2}
+			},
+			false,
+		},
+		{
+			"liveness probe with success threshold",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Driver.LivenessProbe = &ContainerProbeSpec{SuccessThreshold: 2}
+			},
+			true,
+		},
+		{
+			"negative probe delay",
+			func(cp *ClusterPolicy) {
+				cp.Spec.Driver.StartupProbe = &ContainerProbeSpec{InitialDelaySeconds: -1}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cp := newSampleClusterPolicy(t)
+			tc.update(cp)
+			err := cp.Validate()
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClusterPolicyValidateUpdate(t *testing.T) {
+	invalid := func(cp *ClusterPolicy) {
+		cp.Spec.Driver.LivenessProbe = &ContainerProbeSpec{SuccessThreshold: 2}
+	}
+	testCases := []struct {
+		description string
+		// old is applied to the stored ClusterPolicy, update to the updated one
+		old         func(cp *ClusterPolicy)
+		update      func(cp *ClusterPolicy)
+		expectError bool
+	}{
+		{
+			description: "invalid spec",
+			old:         func(cp *ClusterPolicy) {},
+			update:      invalid,
+			expectError: true,
+		},
+		{
+			description: "spec changed while invalid",
+			old:         invalid,
+			update: func(cp *ClusterPolicy) {
+				invalid(cp)
+				cp.Spec.DevicePlugin.Enabled = newBoolPtr(false)
+			},
+			expectError: true,
+		},
+		{
+			description: "metadata update of an invalid policy",
+			old:         invalid,
+			update: func(cp *ClusterPolicy) {
+				invalid(cp)
+				cp.Labels = map[string]string{"team": "ml"}
+			},
+		},
+		{
+			description: "finalizer removal of an invalid policy being deleted",
+			old: func(cp *ClusterPolicy) {
+				invalid(cp)
+				cp.Finalizers = []string{testFinalizer}
+			},
+			update: func(cp *ClusterPolicy) {
+				invalid(cp)
+				now := metav1.Now()
+				cp.DeletionTimestamp = &now
+				cp.Finalizers = nil
+			},
+		},
+		{
+			description: "defaults set explicitly on an invalid policy",
+			old:         invalid,
+			update: func(cp *ClusterPolicy) {
+				invalid(cp)
+				cp.Spec.Operator.Paused = newBoolPtr(false)
+				cp.Spec.DevicePlugin.Paused = newBoolPtr(false)
+			},
+		},
+		{
+			description: "component paused on an invalid policy",
+			old:         invalid,
+			update: func(cp *ClusterPolicy) {
+				invalid(cp)
+				cp.Spec.DevicePlugin.Paused = newBoolPtr(true)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// the stored policy was created before the webhook, it is not defaulted
+			old := newSampleClusterPolicy(t)
+			tc.old(old)
+			cp := old.DeepCopy()
+			tc.update(cp)
+			cp.Default()
+			_, err := cp.ValidateUpdate(old)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClusterPolicyDefault(t *testing.T) {
+	cp := &ClusterPolicy{}
+	cp.Default()
+
+	require.NotNil(t, cp.Spec.Driver.Enabled)
+	require.True(t, *cp.Spec.Driver.Enabled)
+	require.NotNil(t, cp.Spec.Toolkit.Enabled)
+	require.True(t, *cp.Spec.Toolkit.Enabled)
+	require.NotNil(t, cp.Spec.NodeStatusExporter.Enabled)
+	require.False(t, *cp.Spec.NodeStatusExporter.Enabled)
+	require.NotNil(t, cp.Spec.CDI.Default)
+	require.False(t, *cp.Spec.CDI.Default)
+	for _, paused := range []*bool{
+		cp.Spec.Operator.Paused,
+		cp.Spec.Driver.Paused,
+		cp.Spec.Toolkit.Paused,
+		cp.Spec.DevicePlugin.Paused,
+		cp.Spec.NodeStatusExporter.Paused,
+		cp.Spec.GPUFeatureDiscovery.Paused,
+		cp.Spec.Validator.Paused,
+	} {
+		require.NotNil(t, paused)
+		require.False(t, *paused)
+	}
+
+	// explicit values must be preserved
+	cp = &ClusterPolicy{}
+	cp.Spec.DevicePlugin.Enabled = newBoolPtr(false)
+	cp.Spec.Driver.Paused = newBoolPtr(true)
+	cp.Default()
+	require.False(t, *cp.Spec.DevicePlugin.Enabled)
+	require.True(t, *cp.Spec.Driver.Paused)
+}
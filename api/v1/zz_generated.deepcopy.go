@@ -24,6 +24,7 @@ package v1
 import (
 	"github.com/NVIDIA/k8s-operator-libs/api/upgrade/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -58,7 +59,7 @@ func (in *ClusterPolicy) DeepCopyInto(out *ClusterPolicy) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPolicy.
@@ -140,6 +141,25 @@ func (in *ClusterPolicySpec) DeepCopy() *ClusterPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterPolicyStatus) DeepCopyInto(out *ClusterPolicyStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.States != nil {
+		in, out := &in.States, &out.States
+		*out = make([]StateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageDigests != nil {
+		in, out := &in.ImageDigests, &out.ImageDigests
+		*out = make([]ImageDigestStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPolicyStatus.
@@ -249,13 +269,21 @@ func (in *DevicePluginSpec) DeepCopyInto(out *DevicePluginSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(DevicePluginConfig)
 		**out = **in
 	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePluginSpec.
@@ -314,7 +342,9 @@ func (in *DriverManagerSpec) DeepCopyInto(out *DriverManagerSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -400,7 +430,9 @@ func (in *DriverSpec) DeepCopyInto(out *DriverSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.RepoConfig != nil {
 		in, out := &in.RepoConfig, &out.RepoConfig
@@ -427,6 +459,12 @@ func (in *DriverSpec) DeepCopyInto(out *DriverSpec) {
 		*out = new(KernelModuleConfigSpec)
 		**out = **in
 	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverSpec.
@@ -445,7 +483,9 @@ func (in *DriverValidatorSpec) DeepCopyInto(out *DriverValidatorSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -462,6 +502,11 @@ func (in *DriverValidatorSpec) DeepCopy() *DriverValidatorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvVar) DeepCopyInto(out *EnvVar) {
 	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(corev1.EnvVarSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVar.
@@ -520,7 +565,9 @@ func (in *GPUDirectStorageSpec) DeepCopyInto(out *GPUDirectStorageSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -560,8 +607,16 @@ func (in *GPUFeatureDiscoverySpec) DeepCopyInto(out *GPUFeatureDiscoverySpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUFeatureDiscoverySpec.
@@ -574,6 +629,36 @@ func (in *GPUFeatureDiscoverySpec) DeepCopy() *GPUFeatureDiscoverySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDigestStatus) DeepCopyInto(out *ImageDigestStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDigestStatus.
+func (in *ImageDigestStatus) DeepCopy() *ImageDigestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDigestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRegistryMirror) DeepCopyInto(out *ImageRegistryMirror) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRegistryMirror.
+func (in *ImageRegistryMirror) DeepCopy() *ImageRegistryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRegistryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InitContainerSpec) DeepCopyInto(out *InitContainerSpec) {
 	*out = *in
@@ -635,8 +720,16 @@ func (in *NodeStatusExporterSpec) DeepCopyInto(out *NodeStatusExporterSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatusExporterSpec.
@@ -667,6 +760,21 @@ func (in *OperatorSpec) DeepCopyInto(out *OperatorSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ImageRegistryMirrors != nil {
+		in, out := &in.ImageRegistryMirrors, &out.ImageRegistryMirrors
+		*out = make([]ImageRegistryMirror, len(*in))
+		copy(*out, *in)
+	}
+	if in.PinImageDigests != nil {
+		in, out := &in.PinImageDigests, &out.PinImageDigests
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorSpec.
@@ -725,7 +833,9 @@ func (in *PluginValidatorSpec) DeepCopyInto(out *PluginValidatorSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -783,6 +893,61 @@ func (in *RollingUpdateSpec) DeepCopy() *RollingUpdateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingSpec) DeepCopyInto(out *SchedulingSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingSpec.
+func (in *SchedulingSpec) DeepCopy() *SchedulingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateStatus) DeepCopyInto(out *StateStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateStatus.
+func (in *StateStatus) DeepCopy() *StateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ToolkitSpec) DeepCopyInto(out *ToolkitSpec) {
 	*out = *in
@@ -809,8 +974,16 @@ func (in *ToolkitSpec) DeepCopyInto(out *ToolkitSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolkitSpec.
@@ -829,7 +1002,9 @@ func (in *ToolkitValidatorSpec) DeepCopyInto(out *ToolkitValidatorSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -867,8 +1042,16 @@ func (in *ValidatorSpec) DeepCopyInto(out *ValidatorSpec) {
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
 	}
+	in.SchedulingSpec.DeepCopyInto(&out.SchedulingSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidatorSpec.
@@ -0,0 +1,185 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+type action string
+
+const (
+	actionNone   action = "unchanged"
+	actionCreate action = "create"
+	actionUpdate action = "update"
+	actionDelete action = "delete"
+)
+
+// objectDiff describes how applying the candidate ClusterPolicy changes an object
+type objectDiff struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Action     action `json:"action"`
+	// Rollout is set for DaemonSets the operator updates, as the hash of their spec changed
+	Rollout bool          `json:"rollout,omitempty"`
+	Changes []fieldChange `json:"changes,omitempty"`
+}
+
+// fieldChange is a single field of an object that differs from the cluster
+type fieldChange struct {
+	Path     string      `json:"path"`
+	Live     interface{} `json:"live,omitempty"`
+	Rendered interface{} `json:"rendered,omitempty"`
+}
+
+func newObjectDiff(obj client.Object) objectDiff {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return objectDiff{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Action:     actionNone,
+	}
+}
+
+func (d objectDiff) objectName() string {
+	if d.Namespace == "" {
+		return d.Name
+	}
+	return d.Namespace + "/" + d.Name
+}
+
+func (c fieldChange) String() string {
+	switch {
+	case c.Live == nil:
+		return fmt.Sprintf("+ %s: %s", c.Path, toJSON(c.Rendered))
+	case c.Rendered == nil:
+		return fmt.Sprintf("- %s: %s", c.Path, toJSON(c.Live))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, toJSON(c.Live), toJSON(c.Rendered))
+	}
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func objectName(obj client.Object) string {
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return obj.GetObjectKind().GroupVersionKind().Kind + " " + name
+}
+
+// ignoredPaths are set by the API server or the operator at runtime, and are
+// not part of what the operator applies
+var ignoredPaths = map[string]bool{
+	"status":                     true,
+	"metadata.uid":               true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.creationTimestamp": true,
+	"metadata.managedFields":     true,
+	"metadata.selfLink":          true,
+	joinPath("metadata.annotations", controllers.NvidiaAnnotationHashKey): true,
+}
+
+// compare returns the fields of rendered which differ from live. Fields only
+// set in live are defaulted by the API server and are not reported, unless
+// they are part of a list whose length changed
+func compare(path string, rendered, live interface{}) []fieldChange {
+	if ignoredPaths[path] {
+		return nil
+	}
+
+	switch r := rendered.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if len(r) == 0 {
+				return nil
+			}
+			return []fieldChange{{Path: path, Live: live, Rendered: rendered}}
+		}
+		keys := make([]string, 0, len(r))
+		for key := range r {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var changes []fieldChange
+		for _, key := range keys {
+			changes = append(changes, compare(joinPath(path, key), r[key], l[key])...)
+		}
+		return changes
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			if len(r) == 0 {
+				return nil
+			}
+			return []fieldChange{{Path: path, Live: live, Rendered: rendered}}
+		}
+
+		var changes []fieldChange
+		for i := range r {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(l) {
+				changes = append(changes, fieldChange{Path: elemPath, Rendered: r[i]})
+				continue
+			}
+			changes = append(changes, compare(elemPath, r[i], l[i])...)
+		}
+		for i := len(r); i < len(l); i++ {
+			changes = append(changes, fieldChange{Path: fmt.Sprintf("%s[%d]", path, i), Live: l[i]})
+		}
+		return changes
+	default:
+		if reflect.DeepEqual(rendered, live) {
+			return nil
+		}
+		return []fieldChange{{Path: path, Live: live, Rendered: rendered}}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
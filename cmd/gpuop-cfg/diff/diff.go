@@ -0,0 +1,388 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/render"
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	clusterPolicy string
+	assetsDir     string
+	namespace     string
+	kubeconfig    string
+	output        string
+	serverDryRun  bool
+}
+
+// NewCommand constructs a diff command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'diff' command
+	c := cli.Command{
+		Name:  "diff",
+		Usage: "Show how applying a clusterpolicy changes the operand objects in a cluster",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "clusterpolicy",
+			Usage:       "Specify the file containing the candidate clusterpolicy yaml. If this is '-' the file is read from STDIN",
+			Value:       "-",
+			Destination: &opts.clusterPolicy,
+		},
+		&cli.StringFlag{
+			Name:        "assets",
+			Usage:       "Specify the directory containing the assets of all states, matching the version of the operator in the cluster. The assets embedded in gpuop-cfg are used if unset",
+			Destination: &opts.assetsDir,
+		},
+		&cli.StringFlag{
+			Name:        "namespace",
+			Usage:       "Specify the namespace the GPU Operator is installed in",
+			Value:       "gpu-operator",
+			Destination: &opts.namespace,
+		},
+		&cli.StringFlag{
+			Name:        "kubeconfig",
+			Usage:       "Specify the kubeconfig file of the cluster. The default loading rules of kubectl apply if unset",
+			Destination: &opts.kubeconfig,
+			EnvVars:     []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the output format, one of [text, json]",
+			Value:       outputText,
+			Destination: &opts.output,
+		},
+		&cli.BoolFlag{
+			Name:        "server-dry-run",
+			Usage:       "Apply the candidate clusterpolicy in dry-run mode to obtain the defaults set by the API server",
+			Value:       true,
+			Destination: &opts.serverDryRun,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	switch opts.output {
+	case outputText, outputJSON:
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of [%s, %s]", opts.output, outputText, outputJSON)
+	}
+	if opts.namespace == "" {
+		return fmt.Errorf("the namespace must be specified")
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	ctx := c.Context
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: opts.kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+
+	s, err := controllers.NewRenderScheme()
+	if err != nil {
+		return fmt.Errorf("failed to create scheme: %v", err)
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %v", err)
+	}
+
+	cp, err := render.LoadClusterPolicy(opts.clusterPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to load clusterpolicy: %v", err)
+	}
+	cp, err = m.resolveClusterPolicy(ctx, kubeClient, cp, opts.serverDryRun)
+	if err != nil {
+		return err
+	}
+
+	nodes := &corev1.NodeList{}
+	err = kubeClient.List(ctx, nodes)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	k8sVersion, err := kubernetesVersion(cfg)
+	if err != nil {
+		return err
+	}
+
+	renderOpts := controllers.RenderOptions{
+		AssetsDir:         opts.assetsDir,
+		Namespace:         opts.namespace,
+		KubernetesVersion: k8sVersion,
+		Nodes:             nodes.Items,
+	}
+	if m.logger.IsLevelEnabled(logrus.DebugLevel) {
+		renderOpts.Log = logr.New(render.NewLogSink(m.logger))
+	}
+	result, err := controllers.Render(ctx, cp, renderOpts)
+	if err != nil {
+		return fmt.Errorf("failed to render clusterpolicy: %v", err)
+	}
+
+	diffs, err := diffObjects(ctx, kubeClient, result)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == outputJSON {
+		return writeJSON(c.App.Writer, diffs)
+	}
+	return writeText(c.App.Writer, diffs)
+}
+
+// resolveClusterPolicy returns the candidate ClusterPolicy as the API server would store it.
+// The candidate is compared against the active ClusterPolicy, the oldest one, as the operator
+// ignores all other instances. The UID of the active ClusterPolicy is reused, as it is part of
+// the owner reference of every operand object and hence of the hash of the DaemonSets
+func (m command) resolveClusterPolicy(ctx context.Context, c client.Client, cp *v1.ClusterPolicy, serverDryRun bool) (*v1.ClusterPolicy, error) {
+	list := &v1.ClusterPolicyList{}
+	err := c.List(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusterpolicies: %v", err)
+	}
+
+	live := controllers.ActiveClusterPolicy(list.Items)
+	if live == nil {
+		m.logger.Warnf("No active ClusterPolicy found in the cluster, all operand objects are new")
+		if serverDryRun {
+			err = c.Create(ctx, cp, client.DryRunAll)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create clusterpolicy in dry-run mode: %v", err)
+			}
+		}
+		return cp, nil
+	}
+
+	if live.Name != cp.Name {
+		for _, item := range list.Items {
+			if item.Name != cp.Name {
+				continue
+			}
+			// the state may not be set yet if the operator did not reconcile the ClusterPolicy
+			state := "not the active one"
+			if item.Status.State == v1.Ignored {
+				state = string(v1.Ignored)
+			}
+			m.logger.Warnf("ClusterPolicy %s is %s, the operator only reconciles the oldest ClusterPolicy %s: applying %s does not change any operand object",
+				cp.Name, state, live.Name, cp.Name)
+		}
+		m.logger.Infof("Comparing against the active ClusterPolicy %s found in the cluster", live.Name)
+		cp.Name = live.Name
+	}
+	cp.UID = live.UID
+	cp.ResourceVersion = live.ResourceVersion
+	cp.Generation = live.Generation
+	cp.Status = live.Status
+
+	if serverDryRun {
+		err = c.Update(ctx, cp, client.DryRunAll)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update clusterpolicy in dry-run mode: %v", err)
+		}
+	}
+	return cp, nil
+}
+
+func kubernetesVersion(cfg *rest.Config) (string, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return "", fmt.Errorf("error building discovery client: %v", err)
+	}
+
+	info, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch server version information: %v", err)
+	}
+
+	return info.GitVersion, nil
+}
+
+// diffObjects compares the rendered objects against the objects in the cluster
+func diffObjects(ctx context.Context, c client.Client, result *controllers.RenderResult) ([]objectDiff, error) {
+	diffs := []objectDiff{}
+
+	for _, obj := range result.Objects {
+		live, err := getLive(ctx, c, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %v", objectName(obj), err)
+		}
+
+		d := newObjectDiff(obj)
+		if live == nil {
+			d.Action = actionCreate
+			diffs = append(diffs, d)
+			continue
+		}
+
+		d.Changes = compare("", rendered, live.Object)
+		if len(d.Changes) != 0 {
+			d.Action = actionUpdate
+		}
+
+		if d.Kind == "DaemonSet" {
+			// the operator updates a DaemonSet whenever the hash of its spec changes,
+			// regardless of whether the diff of the fields is empty
+			liveHash := live.GetAnnotations()[controllers.NvidiaAnnotationHashKey]
+			renderedHash := obj.GetAnnotations()[controllers.NvidiaAnnotationHashKey]
+			if liveHash != renderedHash {
+				d.Action = actionUpdate
+				d.Rollout = true
+			}
+		}
+		diffs = append(diffs, d)
+	}
+
+	for _, obj := range result.Deleted {
+		live, err := getLive(ctx, c, obj)
+		if err != nil {
+			return nil, err
+		}
+		if live == nil {
+			continue
+		}
+		d := newObjectDiff(obj)
+		d.Action = actionDelete
+		diffs = append(diffs, d)
+	}
+
+	return diffs, nil
+}
+
+// getLive returns obj as found in the cluster, or nil if it does not exist.
+// The namespace of obj is cleared if its kind is cluster scoped
+func getLive(ctx context.Context, c client.Client, obj client.Object) (*unstructured.Unstructured, error) {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	key := client.ObjectKeyFromObject(obj)
+	namespaced, err := c.IsObjectNamespaced(live)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// the kind is not served by the cluster, eg a missing CRD
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scope of %s: %v", objectName(obj), err)
+	}
+	if !namespaced {
+		// the states set the operator namespace on cluster scoped objects as well
+		obj.SetNamespace("")
+		key.Namespace = ""
+	}
+
+	err = c.Get(ctx, key, live)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %v", objectName(obj), err)
+	}
+	return live, nil
+}
+
+func writeJSON(w io.Writer, diffs []objectDiff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diffs)
+}
+
+func writeText(w io.Writer, diffs []objectDiff) error {
+	var rollouts []string
+	for _, d := range diffs {
+		if d.Action == actionNone {
+			continue
+		}
+		header := fmt.Sprintf("%s %s: %s", d.Kind, d.objectName(), d.Action)
+		if d.Rollout {
+			header += " (rollout)"
+			rollouts = append(rollouts, d.objectName())
+		}
+		fmt.Fprintln(w, header)
+		for _, change := range d.Changes {
+			fmt.Fprintf(w, "  %s\n", change)
+		}
+	}
+
+	if len(rollouts) == 0 {
+		fmt.Fprintln(w, "No DaemonSet would roll out")
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "DaemonSets which would roll out: %s\n", strings.Join(rollouts, ", "))
+	return err
+}
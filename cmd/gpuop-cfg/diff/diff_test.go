@@ -0,0 +1,141 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package diff
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+)
+
+func TestResolveClusterPolicy(t *testing.T) {
+	created := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	newClusterPolicy := func(name string, age time.Duration, state v1.State) *v1.ClusterPolicy {
+		return &v1.ClusterPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				UID:               types.UID(name + "-uid"),
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Status: v1.ClusterPolicyStatus{State: state},
+		}
+	}
+
+	testCases := []struct {
+		description string
+		live        []*v1.ClusterPolicy
+		name        string
+		expected    string
+		// warning is a substring of the expected warning, if any
+		warning string
+	}{
+		{
+			description: "no clusterpolicy",
+			name:        "cluster-policy",
+			expected:    "cluster-policy",
+			warning:     "No active ClusterPolicy found in the cluster",
+		},
+		{
+			description: "same name",
+			live:        []*v1.ClusterPolicy{newClusterPolicy("cluster-policy", 0, v1.Ready)},
+			name:        "cluster-policy",
+			expected:    "cluster-policy",
+		},
+		{
+			description: "other name",
+			live:        []*v1.ClusterPolicy{newClusterPolicy("gpu-cluster-policy", 0, v1.Ready)},
+			name:        "cluster-policy",
+			expected:    "gpu-cluster-policy",
+		},
+		{
+			description: "oldest clusterpolicy is active",
+			live: []*v1.ClusterPolicy{
+				newClusterPolicy("newer", 0, v1.Ignored),
+				newClusterPolicy("older", time.Hour, v1.Ready),
+			},
+			name:     "cluster-policy",
+			expected: "older",
+		},
+		{
+			description: "ignored clusterpolicy",
+			live: []*v1.ClusterPolicy{
+				newClusterPolicy("newer", 0, v1.Ignored),
+				newClusterPolicy("older", time.Hour, v1.Ready),
+			},
+			name:     "newer",
+			expected: "older",
+			warning:  "ClusterPolicy newer is ignored, the operator only reconciles the oldest ClusterPolicy older",
+		},
+		{
+			description: "clusterpolicy not reconciled yet",
+			live: []*v1.ClusterPolicy{
+				newClusterPolicy("newer", 0, ""),
+				newClusterPolicy("older", time.Hour, v1.Ready),
+			},
+			name:     "newer",
+			expected: "older",
+			warning:  "ClusterPolicy newer is not the active one, the operator only reconciles the oldest ClusterPolicy older",
+		},
+	}
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(s))
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			objs := make([]client.Object, 0, len(tc.live))
+			for _, cp := range tc.live {
+				objs = append(objs, cp)
+			}
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+			var log bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&log)
+			m := command{logger: logger}
+
+			candidate := &v1.ClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: tc.name}}
+			cp, err := m.resolveClusterPolicy(context.Background(), c, candidate, false)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cp.Name)
+			for _, live := range tc.live {
+				if live.Name == tc.expected {
+					require.Equal(t, live.UID, cp.UID)
+					require.Equal(t, live.Status.State, cp.Status.State)
+				}
+			}
+
+			if tc.warning == "" {
+				require.NotContains(t, log.String(), "level=warning")
+			} else {
+				require.Contains(t, log.String(), "level=warning")
+				require.Contains(t, log.String(), tc.warning)
+			}
+		})
+	}
+}
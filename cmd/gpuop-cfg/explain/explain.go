@@ -0,0 +1,283 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package explain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/initpolicy"
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	crd    string
+	states string
+}
+
+// NewCommand constructs an explain command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'explain' command
+	c := cli.Command{
+		Name:      "explain",
+		Usage:     "Explain a ClusterPolicy field, the operand settings it controls and the operands restarted when it changes",
+		ArgsUsage: "spec.<field path>",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "crd",
+			Usage:       "Specify the file containing the ClusterPolicy CRD whose descriptions and defaults document the fields. The CRD embedded in gpuop-cfg is used if unset",
+			Destination: &opts.crd,
+		},
+		&cli.StringFlag{
+			Name:        "states",
+			Usage:       "Specify the comma-separated list of the states deployed by the operator, as set by its --states flag. Its default states apply if unset",
+			Destination: &opts.states,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("exactly one field must be specified, such as spec.devicePlugin.config")
+	}
+	if _, err := controllers.ParseOperandStates(opts.states); err != nil {
+		return fmt.Errorf("invalid --states: %v", err)
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	specSchema, err := initpolicy.LoadSpecSchema(opts.crd)
+	if err != nil {
+		return fmt.Errorf("failed to load CRD schema: %v", err)
+	}
+
+	states, err := controllers.ParseOperandStates(opts.states)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := explainField(specSchema, c.Args().First(), states)
+	if err != nil {
+		return err
+	}
+	_, err = c.App.Writer.Write(explanation)
+	return err
+}
+
+// explainField describes the spec field at path, with its type, description and
+// default, and the operand settings derived from it. states are the states deployed by
+// the operator, its default states if empty
+func explainField(specSchema *apiextensionsv1.JSONSchemaProps, path string, states []string) ([]byte, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "spec.")
+	if path == "" || path == "spec" {
+		return nil, fmt.Errorf("a field of the spec must be specified, such as spec.devicePlugin.config")
+	}
+
+	defaulted := &v1.ClusterPolicy{}
+	defaulted.Default()
+
+	t, v, schema := reflect.TypeOf(defaulted.Spec), reflect.ValueOf(defaulted.Spec), specSchema
+	parent := "spec"
+	for _, name := range strings.Split(path, ".") {
+		// fields of lists and maps are those of their items
+		t, v = elem(t, v)
+		switch {
+		case schema.Items != nil && schema.Items.Schema != nil:
+			schema = schema.Items.Schema
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+			schema = schema.AdditionalProperties.Schema
+		}
+
+		prop, ok := schema.Properties[name]
+		if !ok {
+			names := make([]string, 0, len(schema.Properties))
+			for name := range schema.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown field %q of %s, must be one of [%s]", name, parent, strings.Join(names, ", "))
+		}
+		schema = &prop
+		parent += "." + name
+
+		if t.Kind() != reflect.Struct {
+			// the schema documents the fields of the types which are not structs, such as quantities
+			t, v = nil, reflect.Value{}
+			continue
+		}
+		sf, fv, ok := structField(t, v, name)
+		if !ok {
+			return nil, fmt.Errorf("field %s is not part of the ClusterPolicy API", parent)
+		}
+		t, v = sf.Type, fv
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "FIELD:    %s\n", parent)
+	typ := schema.Type
+	if t != nil && t.String() != schema.Type {
+		typ = fmt.Sprintf("%s (%s)", t.String(), schema.Type)
+	}
+	fmt.Fprintf(buf, "TYPE:     %s\n", typ)
+	fmt.Fprintf(buf, "DEFAULT:  %s\n", defaultValue(schema, t, v))
+	if len(schema.Enum) != 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, strings.Trim(string(value.Raw), `"`))
+		}
+		fmt.Fprintf(buf, "ALLOWED:  %s\n", strings.Join(values, ", "))
+	}
+
+	fmt.Fprintln(buf, "\nDESCRIPTION:")
+	description := strings.TrimSpace(schema.Description)
+	if description == "" {
+		description = "<none>"
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(buf, "    %s\n", line)
+	}
+
+	effects := controllers.SpecFieldEffects(path)
+	fmt.Fprintln(buf, "\nCONTROLS:")
+	if len(effects) == 0 {
+		fmt.Fprintln(buf, "    no known operand setting")
+	}
+	var restarted []string
+	for _, effect := range effects {
+		operand := effect.Operand
+		if operand == "" {
+			operand = "operator"
+		} else if !controllers.OperandDeployed(operand, states) {
+			if len(states) == 0 {
+				operand += " (not deployed by the operator by default)"
+			} else {
+				operand += " (not deployed by the operator with the given states)"
+			}
+		}
+		fmt.Fprintf(buf, "    %s: %s\n", operand, effect.Setting)
+		if effect.Restarts && (len(restarted) == 0 || restarted[len(restarted)-1] != operand) {
+			restarted = append(restarted, operand)
+		}
+	}
+
+	fmt.Fprintln(buf, "\nRESTARTS:")
+	if len(restarted) == 0 {
+		fmt.Fprintln(buf, "    no operand is restarted when the field changes")
+	}
+	for _, operand := range restarted {
+		fmt.Fprintf(buf, "    %s\n", operand)
+	}
+	return buf.Bytes(), nil
+}
+
+// elem returns the type and value of the items of lists, maps and pointers
+func elem(t reflect.Type, v reflect.Value) (reflect.Type, reflect.Value) {
+	if t == nil {
+		return nil, v
+	}
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+			if v.IsValid() && !v.IsNil() {
+				v = v.Elem()
+			} else {
+				v = reflect.Value{}
+			}
+		case reflect.Slice, reflect.Map:
+			t, v = t.Elem(), reflect.Value{}
+		default:
+			return t, v
+		}
+	}
+}
+
+// structField returns the field of the struct type t with the given json name, and its
+// value in v, following inlined structs
+func structField(t reflect.Type, v reflect.Value, name string) (reflect.StructField, reflect.Value, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && len(tag) > 1 && tag[1] == "inline" {
+			if inlined, inlinedValue, ok := structField(sf.Type, fv, name); ok {
+				return inlined, inlinedValue, true
+			}
+			continue
+		}
+		if tag[0] == name {
+			return sf, fv, true
+		}
+	}
+	return reflect.StructField{}, reflect.Value{}, false
+}
+
+// defaultValue returns the default of a field, set by the CRD or by the defaulting webhook
+func defaultValue(schema *apiextensionsv1.JSONSchemaProps, t reflect.Type, v reflect.Value) string {
+	if schema.Default != nil {
+		return string(schema.Default.Raw)
+	}
+	if t == nil || !v.IsValid() || v.IsZero() {
+		return "<none>"
+	}
+	// structs hold the defaults of their fields, which are explained separately
+	if et, _ := elem(t, v); et.Kind() == reflect.Struct {
+		return "<none>"
+	}
+	value, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "<none>"
+	}
+	return string(value) + " (set by the defaulting webhook)"
+}
@@ -0,0 +1,133 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package explain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/initpolicy"
+)
+
+func TestExplainField(t *testing.T) {
+	specSchema, err := initpolicy.LoadSpecSchema("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description   string
+		path          string
+		states        []string
+		errorExpected bool
+		// expected are substrings of the explanation
+		expected []string
+	}{
+		{
+			description: "cdi default",
+			path:        "spec.cdi.default",
+			expected: []string{
+				"FIELD:    spec.cdi.default\n",
+				"TYPE:     *bool (boolean)\n",
+				"DEFAULT:  false\n",
+				"container-toolkit: NVIDIA_CONTAINER_RUNTIME_MODE=cdi env",
+				"RESTARTS:\n    container-toolkit\n",
+			},
+		},
+		{
+			description: "struct field",
+			path:        "spec.devicePlugin.config",
+			expected: []string{
+				"TYPE:     *v1.DevicePluginConfig (object)\n",
+				"Configuration for the NVIDIA Device Plugin via the ConfigMap",
+				"device-plugin: CONFIG_FILE=/config/config.yaml env",
+				"RESTARTS:\n    device-plugin\n",
+			},
+		},
+		{
+			description: "webhook default without spec prefix",
+			path:        "devicePlugin.enabled",
+			expected: []string{
+				"DEFAULT:  true (set by the defaulting webhook)\n",
+				"no operand is restarted when the field changes",
+			},
+		},
+		{
+			description: "enum",
+			path:        "spec.operator.defaultRuntime",
+			expected: []string{
+				"ALLOWED:  docker, crio, containerd\n",
+				"no known operand setting",
+			},
+		},
+		{
+			description: "field of a list item",
+			path:        "spec.daemonsets.tolerations.key",
+			expected: []string{
+				"TYPE:     string\n",
+				"device-plugin: tolerations of the pod",
+			},
+		},
+		{
+			description: "operand not deployed by default",
+			path:        "spec.driver.version",
+			expected: []string{
+				"driver (not deployed by the operator by default): ",
+			},
+		},
+		{
+			description: "operand of a configured state",
+			path:        "spec.driver.version",
+			states:      []string{"pre-requisites", "state-driver"},
+			expected: []string{
+				"    driver: ",
+				"RESTARTS:\n    driver\n",
+			},
+		},
+		{
+			description: "operand not in the configured states",
+			path:        "spec.devicePlugin.config",
+			states:      []string{"pre-requisites", "state-driver"},
+			expected: []string{
+				"device-plugin (not deployed by the operator with the given states): ",
+			},
+		},
+		{
+			description:   "unknown field",
+			path:          "spec.devicePlugin.foo",
+			errorExpected: true,
+		},
+		{
+			description:   "spec",
+			path:          "spec",
+			errorExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			explanation, err := explainField(specSchema, tc.path, tc.states)
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, expected := range tc.expected {
+				require.Contains(t, string(explanation), expected)
+			}
+		})
+	}
+}
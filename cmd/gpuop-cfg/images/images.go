@@ -0,0 +1,217 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package images
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/render"
+)
+
+const (
+	outputList   = "list"
+	outputSkopeo = "skopeo"
+	outputICSP   = "icsp"
+	outputIDMS   = "idms"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	clusterPolicy  string
+	csv            string
+	helmChart      string
+	helmValues     cli.StringSlice
+	assetsDir      string
+	nodes          string
+	output         string
+	mirrorRegistry string
+	name           string
+}
+
+// NewCommand constructs an images command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'images' command
+	c := cli.Command{
+		Name:  "images",
+		Usage: "List all images deployed for a clusterpolicy, a csv or helm values, e.g. to mirror them for disconnected installs",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "clusterpolicy",
+			Usage:       "Specify the file containing the clusterpolicy yaml. If this is '-' the file is read from STDIN",
+			Destination: &opts.clusterPolicy,
+		},
+		&cli.StringFlag{
+			Name:        "csv",
+			Usage:       "Specify the file containing the csv yaml. Its related images, the operator image and the image env defaults of the operator are included, and its alm-example is used if no clusterpolicy is specified",
+			Destination: &opts.csv,
+		},
+		&cli.StringFlag{
+			Name:        "helm-chart",
+			Usage:       "Specify the directory of the gpu-operator helm chart. The clusterpolicy is built from its values if no clusterpolicy is specified",
+			Destination: &opts.helmChart,
+		},
+		&cli.StringSliceFlag{
+			Name:        "helm-values",
+			Usage:       "Specify a values file overriding the values of the helm chart. May be repeated, later files take precedence",
+			Destination: &opts.helmValues,
+		},
+		&cli.StringFlag{
+			Name:        "assets",
+			Usage:       "Specify the directory containing the assets of all states. The assets embedded in gpuop-cfg are used if unset",
+			Destination: &opts.assetsDir,
+		},
+		&cli.StringFlag{
+			Name:        "nodes",
+			Usage:       "Specify a file containing the nodes of the cluster, as a list or as multiple yaml documents. The driver image is listed for the OS and kernel of every node. A single Ubuntu 22.04 GPU node is assumed if unset",
+			Destination: &opts.nodes,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the output format, one of list, skopeo (skopeo sync yaml), icsp (ImageContentSourcePolicy) or idms (ImageDigestMirrorSet)",
+			Value:       outputList,
+			Destination: &opts.output,
+		},
+		&cli.StringFlag{
+			Name:        "mirror-registry",
+			Usage:       "Specify the registry the images are mirrored to, e.g. registry.example.com:5000/gpu-operator. Required for the icsp and idms outputs",
+			Destination: &opts.mirrorRegistry,
+		},
+		&cli.StringFlag{
+			Name:        "name",
+			Usage:       "Specify the name of the ImageContentSourcePolicy or ImageDigestMirrorSet",
+			Value:       "gpu-operator",
+			Destination: &opts.name,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if opts.clusterPolicy == "" && opts.csv == "" && opts.helmChart == "" {
+		return fmt.Errorf("at least one of --clusterpolicy, --csv or --helm-chart must be specified")
+	}
+	if len(opts.helmValues.Value()) != 0 && opts.helmChart == "" {
+		return fmt.Errorf("--helm-values requires --helm-chart")
+	}
+	switch opts.output {
+	case outputList, outputSkopeo:
+	case outputICSP, outputIDMS:
+		if opts.mirrorRegistry == "" {
+			return fmt.Errorf("--mirror-registry must be specified for output %s", opts.output)
+		}
+		opts.mirrorRegistry = strings.TrimSuffix(opts.mirrorRegistry, "/")
+	default:
+		return fmt.Errorf("invalid output format %q, expected one of %s, %s, %s or %s", opts.output, outputList, outputSkopeo, outputICSP, outputIDMS)
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	images := imageSet{}
+	var cp *v1.ClusterPolicy
+
+	if opts.csv != "" {
+		csv, err := loadCSV(opts.csv)
+		if err != nil {
+			return fmt.Errorf("failed to load csv: %v", err)
+		}
+		images.add(csvImages(csv)...)
+
+		// OLM passes the image defaults of the operands to the operator through env
+		for name, value := range csvImageEnv(csv) {
+			if err := os.Setenv(name, value); err != nil {
+				return fmt.Errorf("failed to set %s: %v", name, err)
+			}
+		}
+
+		cp, err = almExample(csv)
+		if err != nil {
+			return fmt.Errorf("failed to get the alm-example of the csv: %v", err)
+		}
+	}
+
+	if opts.helmChart != "" {
+		chart, err := loadChart(opts.helmChart, opts.helmValues.Value())
+		if err != nil {
+			return fmt.Errorf("failed to load helm chart: %v", err)
+		}
+		images.add(chart.images()...)
+
+		cp, err = chart.clusterPolicy()
+		if err != nil {
+			return fmt.Errorf("failed to build clusterpolicy from helm values: %v", err)
+		}
+	}
+
+	if opts.clusterPolicy != "" {
+		var err error
+		cp, err = render.LoadClusterPolicy(opts.clusterPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to load clusterpolicy: %v", err)
+		}
+	}
+
+	if cp == nil {
+		m.logger.Warnf("No clusterpolicy found, the operand images are not listed")
+	} else {
+		operandImages, err := m.operandImages(c.Context, cp, opts)
+		if err != nil {
+			return err
+		}
+		images.add(operandImages...)
+	}
+
+	switch opts.output {
+	case outputSkopeo:
+		return writeSkopeo(c.App.Writer, images)
+	case outputICSP:
+		return writeICSP(c.App.Writer, images, opts.name, opts.mirrorRegistry)
+	case outputIDMS:
+		return writeIDMS(c.App.Writer, images, opts.name, opts.mirrorRegistry)
+	default:
+		return writeList(c.App.Writer, images)
+	}
+}
@@ -0,0 +1,184 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package images
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// update rewrites the golden files with the actual output, e.g.
+// go test ./cmd/gpuop-cfg/images -update
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// assetsDir holds the assets of the states the operand images are rendered from
+const assetsDir = "../../../assets"
+
+// imageEnv are the image env set by the operator deployment of testdata/csv.yaml
+var imageEnv = []string{"CONTAINER_TOOLKIT_IMAGE", "DEVICE_PLUGIN_IMAGE", "VALIDATOR_IMAGE"}
+
+func TestImages(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        []string
+		golden      string
+	}{
+		{
+			description: "clusterpolicy",
+			args:        []string{"--clusterpolicy", "testdata/clusterpolicy.yaml"},
+			golden:      "clusterpolicy.list.golden",
+		},
+		{
+			description: "csv",
+			args:        []string{"--csv", "testdata/csv.yaml"},
+			golden:      "csv.list.golden",
+		},
+		{
+			description: "helm values",
+			args:        []string{"--helm-chart", "testdata/chart", "--helm-values", "testdata/values.yaml"},
+			golden:      "helm.list.golden",
+		},
+		{
+			description: "csv and clusterpolicy",
+			args:        []string{"--csv", "testdata/csv.yaml", "--clusterpolicy", "testdata/clusterpolicy.yaml"},
+			golden:      "csv-clusterpolicy.list.golden",
+		},
+		{
+			description: "skopeo",
+			args:        []string{"--csv", "testdata/csv.yaml", "--clusterpolicy", "testdata/clusterpolicy.yaml", "--output", outputSkopeo},
+			golden:      "csv-clusterpolicy.skopeo.golden",
+		},
+		{
+			description: "icsp",
+			args:        []string{"--csv", "testdata/csv.yaml", "--clusterpolicy", "testdata/clusterpolicy.yaml", "--output", outputICSP, "--mirror-registry", "registry.local:5000/gpu-operator/"},
+			golden:      "csv-clusterpolicy.icsp.golden",
+		},
+		{
+			description: "idms",
+			args:        []string{"--csv", "testdata/csv.yaml", "--clusterpolicy", "testdata/clusterpolicy.yaml", "--output", outputIDMS, "--mirror-registry", "registry.local:5000/gpu-operator", "--name", "mirror"},
+			golden:      "csv-clusterpolicy.idms.golden",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// the csv sets the image env of the operator, restore them after the test
+			for _, name := range imageEnv {
+				t.Setenv(name, "")
+			}
+
+			output, err := runImages(tc.args...)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(output), 0600))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), output)
+
+			if strings.HasSuffix(tc.golden, ".list.golden") {
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				seen := map[string]bool{}
+				for _, line := range lines {
+					require.False(t, seen[line], "image %s listed more than once", line)
+					seen[line] = true
+				}
+			}
+		})
+	}
+}
+
+func TestImagesFlags(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        []string
+		expected    string
+	}{
+		{
+			description: "no source",
+			expected:    "at least one of --clusterpolicy, --csv or --helm-chart must be specified",
+		},
+		{
+			description: "helm values without chart",
+			args:        []string{"--clusterpolicy", "testdata/clusterpolicy.yaml", "--helm-values", "testdata/values.yaml"},
+			expected:    "--helm-values requires --helm-chart",
+		},
+		{
+			description: "mirror registry missing",
+			args:        []string{"--clusterpolicy", "testdata/clusterpolicy.yaml", "--output", outputICSP},
+			expected:    "--mirror-registry must be specified for output icsp",
+		},
+		{
+			description: "invalid output",
+			args:        []string{"--clusterpolicy", "testdata/clusterpolicy.yaml", "--output", "json"},
+			expected:    `invalid output format "json"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := runImages(tc.args...)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestImageSet(t *testing.T) {
+	images := imageSet{}
+	images.add("nvcr.io/nvidia/gpu-operator:v23.6.1", "", " nvcr.io/nvidia/gpu-operator:v23.6.1\n")
+	images.add("nvcr.io/nvidia/cloud-native/gpu-operator-validator:v23.6.1", "nvcr.io/nvidia/gpu-operator@sha256:729f59f650a1c1c6dd2a5f1fc0c792a6664f86f74523b5e6ee0ef2f149bcf089")
+
+	require.Equal(t, []string{
+		"nvcr.io/nvidia/cloud-native/gpu-operator-validator:v23.6.1",
+		"nvcr.io/nvidia/gpu-operator:v23.6.1",
+		"nvcr.io/nvidia/gpu-operator@sha256:729f59f650a1c1c6dd2a5f1fc0c792a6664f86f74523b5e6ee0ef2f149bcf089",
+	}, images.sorted())
+
+	repositories, err := images.repositories()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"nvcr.io/nvidia/cloud-native/gpu-operator-validator",
+		"nvcr.io/nvidia/gpu-operator",
+	}, repositories)
+}
+
+// runImages runs the images command with args against the assets of the
+// repository and returns its output
+func runImages(args ...string) (string, error) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var output bytes.Buffer
+	app := cli.NewApp()
+	app.Writer = &output
+	app.Commands = []*cli.Command{NewCommand(logger)}
+
+	err := app.Run(append([]string{"gpuop-cfg", "images", "--assets", assetsDir}, args...))
+	return output.String(), err
+}
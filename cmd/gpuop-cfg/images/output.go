@@ -0,0 +1,174 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package images
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/regclient/regclient/types/ref"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// imageSet is a deduplicated set of image references
+type imageSet map[string]bool
+
+func (s imageSet) add(images ...string) {
+	for _, image := range images {
+		image = strings.TrimSpace(image)
+		if image == "" {
+			continue
+		}
+		s[image] = true
+	}
+}
+
+func (s imageSet) sorted() []string {
+	images := make([]string, 0, len(s))
+	for image := range s {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// repositories returns the sorted, distinct repositories of the images, e.g. nvcr.io/nvidia/driver
+func (s imageSet) repositories() ([]string, error) {
+	repositories := map[string]bool{}
+	for image := range s {
+		r, err := ref.New(image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %s: %v", image, err)
+		}
+		repositories[r.Registry+"/"+r.Repository] = true
+	}
+
+	sorted := make([]string, 0, len(repositories))
+	for repository := range repositories {
+		sorted = append(sorted, repository)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+func writeList(w io.Writer, images imageSet) error {
+	for _, image := range images.sorted() {
+		if _, err := fmt.Fprintln(w, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skopeoRegistry is a registry of a skopeo sync yaml source file
+type skopeoRegistry struct {
+	// Images maps the repositories to their tags or digests
+	Images map[string][]string `json:"images"`
+}
+
+// writeSkopeo writes the images as the source yaml of 'skopeo sync --src yaml'.
+// Images pinned to a digest are synced by their digest
+func writeSkopeo(w io.Writer, images imageSet) error {
+	registries := map[string]*skopeoRegistry{}
+	for _, image := range images.sorted() {
+		r, err := ref.New(image)
+		if err != nil {
+			return fmt.Errorf("invalid image %s: %v", image, err)
+		}
+		registry, ok := registries[r.Registry]
+		if !ok {
+			registry = &skopeoRegistry{Images: map[string][]string{}}
+			registries[r.Registry] = registry
+		}
+
+		version := r.Tag
+		if r.Digest != "" {
+			version = r.Digest
+		}
+		registry.Images[r.Repository] = append(registry.Images[r.Repository], version)
+	}
+
+	return writeYAML(w, registries)
+}
+
+// mirrorRepository returns the repository source is mirrored to, keeping its path
+// without the registry, e.g. nvcr.io/nvidia/driver is mirrored to <mirror>/nvidia/driver
+func mirrorRepository(mirrorRegistry string, source string) string {
+	path := source
+	if i := strings.IndexByte(source, '/'); i >= 0 {
+		path = source[i+1:]
+	}
+	return mirrorRegistry + "/" + path
+}
+
+func writeICSP(w io.Writer, images imageSet, name string, mirrorRegistry string) error {
+	repositories, err := images.repositories()
+	if err != nil {
+		return err
+	}
+
+	icsp := &operatorv1alpha1.ImageContentSourcePolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: operatorv1alpha1.GroupVersion.String(),
+			Kind:       "ImageContentSourcePolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	for _, repository := range repositories {
+		icsp.Spec.RepositoryDigestMirrors = append(icsp.Spec.RepositoryDigestMirrors, operatorv1alpha1.RepositoryDigestMirrors{
+			Source:  repository,
+			Mirrors: []string{mirrorRepository(mirrorRegistry, repository)},
+		})
+	}
+	return writeYAML(w, icsp)
+}
+
+func writeIDMS(w io.Writer, images imageSet, name string, mirrorRegistry string) error {
+	repositories, err := images.repositories()
+	if err != nil {
+		return err
+	}
+
+	idms := &configv1.ImageDigestMirrorSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: configv1.GroupVersion.String(),
+			Kind:       "ImageDigestMirrorSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	for _, repository := range repositories {
+		idms.Spec.ImageDigestMirrors = append(idms.Spec.ImageDigestMirrors, configv1.ImageDigestMirrors{
+			Source:  repository,
+			Mirrors: []configv1.ImageMirror{configv1.ImageMirror(mirrorRepository(mirrorRegistry, repository))},
+		})
+	}
+	return writeYAML(w, idms)
+}
+
+func writeYAML(w io.Writer, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal yaml: %v", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,336 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/json"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/render"
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+// nfdChart is the name of the node-feature-discovery subchart
+const nfdChart = "node-feature-discovery"
+
+// nodeDriverLabels select the driver image deployed on a node
+var nodeDriverLabels = []string{
+	"feature.node.kubernetes.io/kernel-version.full",
+	"feature.node.kubernetes.io/system-os_release.ID",
+	"feature.node.kubernetes.io/system-os_release.VERSION_ID",
+}
+
+// operandImages renders the operands of cp and returns the images of all
+// their containers, including the images passed through env to the pods
+// spun off by the validator. The operands are rendered once per kind of node,
+// as the driver image depends on the OS and kernel of the node
+func (m command) operandImages(ctx context.Context, cp *v1.ClusterPolicy, opts *options) ([]string, error) {
+	nodes, err := render.LoadNodes(opts.nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %v", err)
+	}
+
+	renderOpts := controllers.RenderOptions{
+		AssetsDir: opts.assetsDir,
+		Namespace: "gpu-operator",
+	}
+	if m.logger.IsLevelEnabled(logrus.DebugLevel) {
+		renderOpts.Log = logr.New(render.NewLogSink(m.logger))
+	}
+
+	var images []string
+	rendered := map[string]bool{}
+	for _, node := range nodes {
+		key := make([]string, 0, len(nodeDriverLabels))
+		for _, label := range nodeDriverLabels {
+			key = append(key, node.Labels[label])
+		}
+		if rendered[strings.Join(key, "/")] {
+			continue
+		}
+		rendered[strings.Join(key, "/")] = true
+
+		renderOpts.Nodes = []corev1.Node{node}
+		result, err := controllers.Render(ctx, cp, renderOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render clusterpolicy for node %s: %v", node.Name, err)
+		}
+		for _, obj := range result.Objects {
+			images = append(images, objectImages(obj)...)
+		}
+	}
+	return images, nil
+}
+
+// objectImages returns the images of the containers of obj and the images set in their env
+func objectImages(obj client.Object) []string {
+	var podSpec *corev1.PodSpec
+	switch o := obj.(type) {
+	case *appsv1.DaemonSet:
+		podSpec = &o.Spec.Template.Spec
+	case *appsv1.Deployment:
+		podSpec = &o.Spec.Template.Spec
+	case *corev1.Pod:
+		podSpec = &o.Spec
+	default:
+		return nil
+	}
+
+	var images []string
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, c := range containers {
+		images = append(images, containerImages(c)...)
+	}
+	return images
+}
+
+// containerImages returns the image of c and the values of its *_IMAGE env
+func containerImages(c corev1.Container) []string {
+	images := []string{c.Image}
+	for _, env := range c.Env {
+		if strings.HasSuffix(env.Name, "_IMAGE") && env.ValueFrom == nil {
+			images = append(images, env.Value)
+		}
+	}
+	return images
+}
+
+func loadCSV(file string) (*v1alpha1.ClusterServiceVersion, error) {
+	contents, err := readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	csv := &v1alpha1.ClusterServiceVersion{}
+	err = yaml.Unmarshal(contents, csv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal csv: %v", err)
+	}
+	return csv, nil
+}
+
+// csvImages returns the related images of csv and the images of its deployments
+func csvImages(csv *v1alpha1.ClusterServiceVersion) []string {
+	var images []string
+	for _, image := range csv.Spec.RelatedImages {
+		images = append(images, image.Image)
+	}
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := deployment.Spec.Template.Spec
+		for _, c := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+			images = append(images, containerImages(c)...)
+		}
+	}
+	return images
+}
+
+// csvImageEnv returns the *_IMAGE env of the operator container of csv
+func csvImageEnv(csv *v1alpha1.ClusterServiceVersion) map[string]string {
+	env := map[string]string{}
+	deployments := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	if len(deployments) == 0 || len(deployments[0].Spec.Template.Spec.Containers) == 0 {
+		return env
+	}
+	for _, e := range deployments[0].Spec.Template.Spec.Containers[0].Env {
+		if strings.HasSuffix(e.Name, "_IMAGE") && e.ValueFrom == nil && e.Value != "" {
+			env[e.Name] = e.Value
+		}
+	}
+	return env
+}
+
+// almExample returns the example clusterpolicy of csv, or nil if it has none
+func almExample(csv *v1alpha1.ClusterServiceVersion) (*v1.ClusterPolicy, error) {
+	example, ok := csv.Annotations["alm-examples"]
+	if !ok {
+		return nil, nil
+	}
+
+	cpList := []v1.ClusterPolicy{}
+	err := json.UnmarshalCaseSensitivePreserveInts([]byte(example), &cpList)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cpList {
+		if cpList[i].Kind == "ClusterPolicy" {
+			return &cpList[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// chart holds the values of the gpu-operator helm chart
+type chart struct {
+	appVersion string
+	values     map[string]interface{}
+	nfd        *chart
+}
+
+// loadChart reads the chart in dir and merges valuesFiles into its values, in order
+func loadChart(dir string, valuesFiles []string) (*chart, error) {
+	c, err := loadChartValues(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range valuesFiles {
+		contents, err := readFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(contents, &values); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal values of %s: %v", file, err)
+		}
+		mergeValues(c.values, values)
+	}
+
+	nfdDir := filepath.Join(dir, "charts", nfdChart)
+	if _, err := os.Stat(nfdDir); err == nil {
+		c.nfd, err = loadChartValues(nfdDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s subchart: %v", nfdChart, err)
+		}
+		if values, ok := c.values[nfdChart].(map[string]interface{}); ok {
+			mergeValues(c.nfd.values, values)
+		}
+	}
+
+	return c, nil
+}
+
+func loadChartValues(dir string) (*chart, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Chart.yaml: %v", err)
+	}
+	metadata := struct {
+		AppVersion string `json:"appVersion"`
+	}{}
+	if err := yaml.Unmarshal(contents, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Chart.yaml: %v", err)
+	}
+
+	c := &chart{appVersion: metadata.AppVersion, values: map[string]interface{}{}}
+	contents, err = os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values.yaml: %v", err)
+	}
+	if err := yaml.Unmarshal(contents, &c.values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal values.yaml: %v", err)
+	}
+	return c, nil
+}
+
+// mergeValues merges src into dst the way helm merges values files: maps are
+// merged recursively, any other value of src replaces the one of dst
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// value returns the value at the given keys of the chart values
+func (c *chart) value(keys ...string) interface{} {
+	var v interface{} = c.values
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func (c *chart) stringValue(keys ...string) string {
+	v := c.value(keys...)
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// images returns the images the chart deploys itself: the operator and NFD
+func (c *chart) images() []string {
+	operatorVersion := c.stringValue("operator", "version")
+	if operatorVersion == "" {
+		operatorVersion = c.appVersion
+	}
+	images := []string{fmt.Sprintf("%s/%s:%s", c.stringValue("operator", "repository"), c.stringValue("operator", "image"), operatorVersion)}
+
+	if enabled, _ := c.value("nfd", "enabled").(bool); enabled && c.nfd != nil {
+		tag := c.nfd.stringValue("image", "tag")
+		if tag == "" {
+			tag = c.nfd.appVersion
+		}
+		images = append(images, fmt.Sprintf("%s:%s", c.nfd.stringValue("image", "repository"), tag))
+	}
+	return images
+}
+
+// clusterPolicy returns the clusterpolicy the chart creates. The components of the
+// spec are configured by the values of the same name
+func (c *chart) clusterPolicy() (*v1.ClusterPolicy, error) {
+	values := runtime.DeepCopyJSON(c.values)
+	// the validator and the node status exporter default to the version of the chart
+	for _, component := range []string{"validator", "nodeStatusExporter"} {
+		if m, ok := values[component].(map[string]interface{}); ok && m["version"] == nil {
+			m["version"] = c.appVersion
+		}
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	cp := &v1.ClusterPolicy{}
+	cp.Name = "cluster-policy"
+	if err := yaml.Unmarshal(data, &cp.Spec); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func readFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(file)
+}
@@ -0,0 +1,332 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package initpolicy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	gpuoperator "github.com/NVIDIA/gpu-operator/deployments/gpu-operator"
+)
+
+// commentWidth is the width descriptions are wrapped at
+const commentWidth = 80
+
+// field is a field of the generated clusterpolicy
+type field struct {
+	name        string
+	description string
+	// value is the default of the field, or the value set by a profile
+	value    interface{}
+	hasValue bool
+	// placeholder is the value shown for fields without value, which are commented out
+	placeholder string
+	// children are the fields of a struct of the ClusterPolicy API
+	children []*field
+	// required fields are set even if they have no value
+	required bool
+}
+
+// active returns whether the field is set in the generated clusterpolicy
+func (f *field) active() bool {
+	if f.hasValue || f.required {
+		return true
+	}
+	for _, child := range f.children {
+		if child.active() {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSpecSchema reads the schema of the ClusterPolicy spec from a CRD manifest, or
+// from the CRD embedded in gpuop-cfg if file is empty
+func LoadSpecSchema(file string) (*apiextensionsv1.JSONSchemaProps, error) {
+	contents := gpuoperator.ClusterPolicyCRD
+	if file != "" {
+		var err error
+		contents, err = os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := yaml.Unmarshal(contents, crd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CRD: %v", err)
+	}
+
+	for _, version := range crd.Spec.Versions {
+		if version.Name != v1.GroupVersion.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		spec, ok := version.Schema.OpenAPIV3Schema.Properties["spec"]
+		if !ok {
+			break
+		}
+		return &spec, nil
+	}
+	return nil, fmt.Errorf("CRD %s has no spec schema for version %q", crd.Name, v1.GroupVersion.Version)
+}
+
+// generate writes a clusterpolicy with every field of the spec, documented by the
+// descriptions of specSchema. Fields are set to the defaults of the CRD and of the
+// defaulting webhook, or to the value set by overrides, keyed by their path under
+// spec. All other fields are commented out
+func generate(specSchema *apiextensionsv1.JSONSchemaProps, overrides map[string]interface{}, header []string) ([]byte, error) {
+	defaulted := &v1.ClusterPolicy{}
+	defaulted.Default()
+
+	fields, err := buildFields(reflect.TypeOf(defaulted.Spec), reflect.ValueOf(defaulted.Spec), specSchema, "", overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, line := range header {
+		fmt.Fprintf(buf, "# %s\n", line)
+	}
+	fmt.Fprintf(buf, "apiVersion: %s\n", v1.GroupVersion.String())
+	fmt.Fprintln(buf, "kind: ClusterPolicy")
+	fmt.Fprintln(buf, "metadata:")
+	fmt.Fprintln(buf, "  name: cluster-policy")
+	fmt.Fprintln(buf, "spec:")
+	if err := writeFields(buf, fields, "  ", 1, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildFields returns the fields of the struct type t, whose defaults are set in v.
+// Only the structs of the ClusterPolicy API and the structs with defaults are
+// expanded, fields of other types, such as the Kubernetes core types, are
+// documented by their schema
+func buildFields(t reflect.Type, v reflect.Value, schema *apiextensionsv1.JSONSchemaProps, path string, overrides map[string]interface{}) ([]*field, error) {
+	var fields []*field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && len(tag) > 1 && tag[1] == "inline" {
+			inlined, err := buildFields(sf.Type, v.Field(i), schema, path, overrides)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inlined...)
+			continue
+		}
+
+		name := tag[0]
+		prop, ok := schema.Properties[name]
+		// fields missing from the schema are dropped by the API server
+		if name == "" || name == "-" || !ok {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		f := &field{name: name, description: describe(&prop)}
+		for _, required := range schema.Required {
+			f.required = f.required || required == name
+		}
+		fields = append(fields, f)
+
+		ft, fv := sf.Type, v.Field(i)
+		switch value, ok := overrides[fieldPath]; {
+		case ok:
+			f.value, f.hasValue = value, true
+		case prop.Default != nil:
+			if err := json.Unmarshal(prop.Default.Raw, &f.value); err != nil {
+				return nil, fmt.Errorf("invalid default of %s: %v", fieldPath, err)
+			}
+			f.hasValue = true
+		case ft.Kind() == reflect.Ptr && !fv.IsNil() && ft.Elem().Kind() != reflect.Struct:
+			// set by the defaulting webhook
+			f.value, f.hasValue = fv.Elem().Interface(), true
+		}
+		if f.hasValue {
+			continue
+		}
+
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if fv.IsNil() {
+				fv = reflect.Zero(ft)
+			} else {
+				fv = fv.Elem()
+			}
+		}
+		if ft.Kind() == reflect.Struct && (ft.PkgPath() == reflect.TypeOf(v1.ClusterPolicySpec{}).PkgPath() || hasDefaults(&prop)) {
+			children, err := buildFields(ft, fv, &prop, fieldPath, overrides)
+			if err != nil {
+				return nil, err
+			}
+			f.children = children
+			continue
+		}
+		f.placeholder = placeholder(&prop)
+	}
+	return fields, nil
+}
+
+// hasDefaults returns whether prop or any of its properties has a default
+func hasDefaults(prop *apiextensionsv1.JSONSchemaProps) bool {
+	if prop.Default != nil {
+		return true
+	}
+	for _, child := range prop.Properties {
+		if hasDefaults(&child) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe returns the description of a field, with the values it is limited to
+func describe(prop *apiextensionsv1.JSONSchemaProps) string {
+	description := strings.TrimSpace(prop.Description)
+	if len(prop.Enum) == 0 {
+		return description
+	}
+
+	values := make([]string, 0, len(prop.Enum))
+	for _, value := range prop.Enum {
+		values = append(values, strings.Trim(string(value.Raw), `"`))
+	}
+	if description != "" {
+		description += "\n"
+	}
+	return description + "Allowed values: " + strings.Join(values, ", ")
+}
+
+// placeholder returns the empty value of the type of a field
+func placeholder(prop *apiextensionsv1.JSONSchemaProps) string {
+	if len(prop.Enum) != 0 {
+		return string(prop.Enum[0].Raw)
+	}
+	switch prop.Type {
+	case "boolean":
+		return "false"
+	case "integer", "number":
+		return "0"
+	case "array":
+		return "[]"
+	case "object":
+		return "{}"
+	}
+	return `""`
+}
+
+// writeFields writes fields indented by indent, at the given nesting level. Fields
+// which are not active are commented out along with their children. The indent
+// of the fields of a commented block holds the comment marker
+func writeFields(buf *bytes.Buffer, fields []*field, indent string, level int, commented bool) error {
+	for i, f := range fields {
+		if level == 1 && i > 0 {
+			buf.WriteString("\n")
+		}
+
+		active := !commented && f.active()
+		prefix, descriptionPrefix := indent, indent+"# "
+		if !active && !commented {
+			// the first field of a commented block
+			prefix, descriptionPrefix = indent+"# ", indent+"# "
+		}
+		writeDescription(buf, descriptionPrefix, f.description)
+
+		switch {
+		case f.hasValue:
+			if err := writeValue(buf, prefix, f.name, f.value); err != nil {
+				return err
+			}
+		case len(f.children) != 0:
+			activeChildren := false
+			for _, child := range f.children {
+				activeChildren = activeChildren || child.active()
+			}
+			if active && !activeChildren {
+				// a required struct without any field set, its fields are commented out
+				fmt.Fprintf(buf, "%s%s: {}\n", prefix, f.name)
+				if err := writeFields(buf, f.children, prefix+"  ", level+1, false); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s:\n", prefix, f.name)
+			if err := writeFields(buf, f.children, prefix+"  ", level+1, !active); err != nil {
+				return err
+			}
+		default:
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, f.name, f.placeholder)
+		}
+	}
+	return nil
+}
+
+// writeDescription writes description as comments starting with prefix, wrapped at commentWidth
+func writeDescription(buf *bytes.Buffer, prefix string, description string) {
+	if description == "" {
+		return
+	}
+	width := commentWidth - len(prefix)
+	for _, paragraph := range strings.Split(description, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len(line)+1+len(word) > width {
+				fmt.Fprintf(buf, "%s%s\n", prefix, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		if line != "" {
+			fmt.Fprintf(buf, "%s%s\n", prefix, line)
+		}
+	}
+}
+
+// writeValue writes a field set to value, as a block if value is not a scalar
+func writeValue(buf *bytes.Buffer, prefix string, name string, value interface{}) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the value of %s: %v", name, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "%s%s: %s\n", prefix, name, lines[0])
+		return nil
+	}
+
+	fmt.Fprintf(buf, "%s%s:\n", prefix, name)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "%s  %s\n", prefix, line)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package initpolicy
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/validate/clusterpolicy"
+)
+
+func TestGenerate(t *testing.T) {
+	// the CRD embedded in gpuop-cfg is used
+	specSchema, err := LoadSpecSchema("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		profiles    []string
+		check       func(*testing.T, *v1.ClusterPolicySpec)
+	}{
+		{
+			description: "defaults",
+			check: func(t *testing.T, spec *v1.ClusterPolicySpec) {
+				require.Equal(t, v1.Docker, spec.Operator.DefaultRuntime)
+				require.True(t, spec.DevicePlugin.IsEnabled())
+				require.False(t, spec.CDI.IsEnabled())
+				require.Empty(t, spec.Operator.ImageRegistryMirrors)
+				require.Equal(t, "25%", spec.Driver.UpgradePolicy.MaxUnavailable.String())
+				require.Equal(t, "nvcr.io/nvidia", spec.Driver.Repository)
+				require.Equal(t, "driver", spec.Driver.Image)
+				require.Equal(t, "535.104.05", spec.Driver.Version)
+			},
+		},
+		{
+			description: "airgapped",
+			profiles:    []string{profileAirgapped},
+			check: func(t *testing.T, spec *v1.ClusterPolicySpec) {
+				require.Equal(t, []v1.ImageRegistryMirror{
+					{Source: "hub.xdxct.com", Mirror: "registry.example.com"},
+					{Source: "nvcr.io", Mirror: "registry.example.com"},
+				}, spec.Operator.ImageRegistryMirrors)
+			},
+		},
+		{
+			description: "cdi",
+			profiles:    []string{profileCDI},
+			check: func(t *testing.T, spec *v1.ClusterPolicySpec) {
+				require.True(t, spec.CDI.IsEnabled())
+				require.True(t, spec.CDI.IsDefault())
+			},
+		},
+		{
+			description: "minimal",
+			profiles:    []string{profileMinimal},
+			check: func(t *testing.T, spec *v1.ClusterPolicySpec) {
+				require.False(t, spec.Driver.IsEnabled())
+				require.False(t, spec.GPUFeatureDiscovery.IsEnabled())
+				require.False(t, spec.NodeStatusExporter.IsEnabled())
+				require.True(t, spec.Toolkit.IsEnabled())
+				require.True(t, spec.DevicePlugin.IsEnabled())
+			},
+		},
+		{
+			description: "combined profiles",
+			profiles:    []string{profileMinimal, profileCDI},
+			check: func(t *testing.T, spec *v1.ClusterPolicySpec) {
+				require.False(t, spec.Driver.IsEnabled())
+				require.True(t, spec.CDI.IsEnabled())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			overrides, err := buildOverrides(tc.profiles, "registry.example.com")
+			require.NoError(t, err)
+
+			contents, err := generate(specSchema, overrides, []string{"test"})
+			require.NoError(t, err)
+
+			findings, err := clusterpolicy.ValidateOffline(context.Background(), contents, "")
+			require.NoError(t, err)
+			require.Empty(t, findings)
+
+			// every field of the spec is documented, set or commented out
+			for name := range specSchema.Properties {
+				require.Regexp(t, regexp.MustCompile(`(?m)^  (# )?`+name+`:`), string(contents))
+			}
+
+			cp := &v1.ClusterPolicy{}
+			require.NoError(t, yaml.Unmarshal(contents, cp))
+			tc.check(t, &cp.Spec)
+
+			// the images of the enabled components are set, as required by 'validate clusterpolicy'
+			mirrors := cp.Spec.Operator.ImageRegistryMirrors
+			for _, c := range []struct {
+				spec    interface{}
+				enabled bool
+			}{
+				{&cp.Spec.Operator.InitContainer, true},
+				{&cp.Spec.Validator, true},
+				{&cp.Spec.Driver, cp.Spec.Driver.IsEnabled()},
+				{&cp.Spec.Driver.Manager, cp.Spec.Driver.IsEnabled()},
+				{&cp.Spec.Toolkit, cp.Spec.Toolkit.IsEnabled()},
+				{&cp.Spec.DevicePlugin, cp.Spec.DevicePlugin.IsEnabled()},
+				{&cp.Spec.NodeStatusExporter, cp.Spec.NodeStatusExporter.IsEnabled()},
+				{&cp.Spec.GPUFeatureDiscovery, cp.Spec.GPUFeatureDiscovery.IsEnabled()},
+			} {
+				if !c.enabled {
+					continue
+				}
+				image, err := v1.ImagePath(c.spec, mirrors)
+				require.NoError(t, err)
+				if len(mirrors) != 0 {
+					require.True(t, strings.HasPrefix(image, "registry.example.com/"), image)
+				}
+			}
+		})
+	}
+}
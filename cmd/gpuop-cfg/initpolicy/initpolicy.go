@@ -0,0 +1,263 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package initpolicy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/validate/clusterpolicy"
+	gpuoperator "github.com/NVIDIA/gpu-operator/deployments/gpu-operator"
+)
+
+const (
+	profileAirgapped = "airgapped"
+	profileCDI       = "cdi"
+	profileMinimal   = "minimal"
+)
+
+// defaultImageRegistries are the registries of the default operand images, which
+// the airgapped profile mirrors
+var defaultImageRegistries = []string{"hub.xdxct.com", "nvcr.io"}
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	profiles       cli.StringSlice
+	mirrorRegistry string
+	crd            string
+	output         string
+}
+
+// NewCommand constructs an init command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'init' command
+	c := cli.Command{
+		Name:  "init",
+		Usage: "Generate a ClusterPolicy with every field documented, optionally pre-filled for common setups",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "profile",
+			Usage:       fmt.Sprintf("Pre-fill the ClusterPolicy for a common setup, one of [%s]. May be repeated", strings.Join(profileNames(), ", ")),
+			Destination: &opts.profiles,
+		},
+		&cli.StringFlag{
+			Name:        "mirror-registry",
+			Usage:       "Specify the registry the images are mirrored to, for the airgapped profile",
+			Value:       "registry.example.com",
+			Destination: &opts.mirrorRegistry,
+		},
+		&cli.StringFlag{
+			Name:        "crd",
+			Usage:       "Specify the file containing the ClusterPolicy CRD whose descriptions and defaults document the fields. The CRD embedded in gpuop-cfg is used if unset",
+			Destination: &opts.crd,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the file the ClusterPolicy is written to. If this is '-' it is written to STDOUT",
+			Value:       "-",
+			Destination: &opts.output,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	for _, profile := range opts.profiles.Value() {
+		if _, err := profileOverrides(profile, opts.mirrorRegistry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	specSchema, err := LoadSpecSchema(opts.crd)
+	if err != nil {
+		return fmt.Errorf("failed to load CRD schema: %v", err)
+	}
+
+	overrides, err := buildOverrides(opts.profiles.Value(), opts.mirrorRegistry)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"ClusterPolicy generated by gpuop-cfg init"}
+	if len(opts.profiles.Value()) != 0 {
+		header[0] += " for the profiles " + strings.Join(opts.profiles.Value(), ", ")
+	}
+	header = append(header,
+		"Fields are set to their default or the value of the profiles, images to those",
+		"of the Helm chart. The fields commented out have no default, uncomment them",
+		"to set them.")
+
+	contents, err := generate(specSchema, overrides, header)
+	if err != nil {
+		return err
+	}
+
+	// the generated clusterpolicy must pass the checks of 'validate clusterpolicy'
+	findings, err := clusterpolicy.ValidateOffline(c.Context, contents, opts.crd)
+	if err != nil {
+		return fmt.Errorf("failed to validate the generated clusterpolicy: %v", err)
+	}
+	if len(findings) != 0 {
+		return fmt.Errorf("the generated clusterpolicy is invalid:\n%s", strings.Join(findings, "\n"))
+	}
+
+	if opts.output == "-" {
+		_, err = c.App.Writer.Write(contents)
+		return err
+	}
+	err = os.WriteFile(opts.output, contents, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write clusterpolicy: %v", err)
+	}
+	m.logger.Infof("Wrote clusterpolicy to %s", opts.output)
+	return nil
+}
+
+// buildOverrides returns the values set in the generated clusterpolicy, keyed by their
+// path under spec: the images of the chart and the values of the profiles
+func buildOverrides(profiles []string, mirrorRegistry string) (map[string]interface{}, error) {
+	overrides, err := chartImages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the images of the chart: %v", err)
+	}
+	for _, profile := range profiles {
+		values, err := profileOverrides(profile, mirrorRegistry)
+		if err != nil {
+			return nil, err
+		}
+		for path, value := range values {
+			overrides[path] = value
+		}
+	}
+	return overrides, nil
+}
+
+// imageComponents are the paths under spec of the components whose image is set to
+// the image of the chart, the values of the chart use the same paths
+var imageComponents = []string{
+	"operator.initContainer",
+	"validator",
+	"driver",
+	"driver.manager",
+	"toolkit",
+	"devicePlugin",
+	"nodeStatusExporter",
+	"gfd",
+}
+
+// chartImages returns the repository, image and version of the components as set
+// by the values of the chart, keyed by their path under spec. The components without
+// a version use the appVersion of the chart, as its templates do
+func chartImages() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(gpuoperator.Values, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal values: %v", err)
+	}
+	chart := struct {
+		AppVersion string `json:"appVersion"`
+	}{}
+	if err := yaml.Unmarshal(gpuoperator.Chart, &chart); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Chart.yaml: %v", err)
+	}
+
+	images := map[string]interface{}{}
+	for _, component := range imageComponents {
+		fields, _, err := unstructured.NestedMap(values, strings.Split(component, ".")...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values of %s: %v", component, err)
+		}
+		for _, name := range []string{"repository", "image", "version"} {
+			value, _ := fields[name].(string)
+			if value == "" && name == "version" {
+				value = chart.AppVersion
+			}
+			if value == "" {
+				return nil, fmt.Errorf("%s.%s is not set", component, name)
+			}
+			images[component+"."+name] = value
+		}
+	}
+	return images, nil
+}
+
+// profileOverrides returns the values a profile sets, keyed by their path under spec
+func profileOverrides(profile string, mirrorRegistry string) (map[string]interface{}, error) {
+	switch profile {
+	case profileAirgapped:
+		// pull every operand image from the mirror, where the images are found under
+		// the same repository without the registry, as synced by 'gpuop-cfg images -o skopeo'
+		var mirrors []interface{}
+		for _, registry := range defaultImageRegistries {
+			mirrors = append(mirrors, map[string]interface{}{"source": registry, "mirror": mirrorRegistry})
+		}
+		return map[string]interface{}{
+			"operator.imageRegistryMirrors": mirrors,
+		}, nil
+	case profileCDI:
+		return map[string]interface{}{
+			"cdi.enabled": true,
+			"cdi.default": true,
+		}, nil
+	case profileMinimal:
+		// the driver is installed on the hosts, only the toolkit and device plugin are deployed
+		return map[string]interface{}{
+			"driver.enabled":             false,
+			"gfd.enabled":                false,
+			"nodeStatusExporter.enabled": false,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown profile %q, must be one of [%s]", profile, strings.Join(profileNames(), ", "))
+}
+
+func profileNames() []string {
+	names := []string{profileAirgapped, profileCDI, profileMinimal}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,100 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package lintassets
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	assetsDir string
+}
+
+// NewCommand constructs a lint-assets command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'lint-assets' command
+	c := cli.Command{
+		Name:  "lint-assets",
+		Usage: "Check the operand manifests decode and match the names the operator transforms look up",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "assets",
+			Usage:       "Specify the directory holding the assets of the states",
+			Value:       "assets",
+			Destination: &opts.assetsDir,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if opts.assetsDir == "" {
+		return fmt.Errorf("the assets directory must be specified")
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	problems, err := controllers.LintAssets(opts.assetsDir)
+	if err != nil {
+		return err
+	}
+
+	errors := 0
+	for _, problem := range problems {
+		severity := "warning"
+		if !problem.Warning {
+			severity = "error"
+			errors++
+		}
+		fmt.Fprintf(c.App.Writer, "%s: %s: %s\n", problem.File, severity, problem.Message)
+	}
+
+	if errors > 0 {
+		return fmt.Errorf("found %d errors in the assets", errors)
+	}
+	m.logger.Infof("Found no errors in the assets, %d warnings", len(problems))
+	return nil
+}
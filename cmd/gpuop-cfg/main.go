@@ -22,7 +22,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	cli "github.com/urfave/cli/v2"
 
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/diff"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/explain"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/images"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/initpolicy"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/lintassets"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/render"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/status"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/supportbundle"
 	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/validate"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/why"
 )
 
 var logger = log.New()
@@ -63,7 +72,16 @@ func main() {
 
 	// Define the subcommands
 	c.Commands = []*cli.Command{
+		initpolicy.NewCommand(logger),
 		validate.NewCommand(logger),
+		render.NewCommand(logger),
+		diff.NewCommand(logger),
+		images.NewCommand(logger),
+		lintassets.NewCommand(logger),
+		supportbundle.NewCommand(logger),
+		status.NewCommand(logger),
+		why.NewCommand(logger),
+		explain.NewCommand(logger),
 	}
 
 	err := c.Run(os.Args)
@@ -0,0 +1,133 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultNode is the node assumed when no nodes are specified: a GPU node
+// labelled by NFD, running containerd
+var defaultNode = corev1.Node{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "gpu-node",
+		Labels: map[string]string{
+			"feature.node.kubernetes.io/pci-10de.present":                   "true",
+			"feature.node.kubernetes.io/kernel-version.full":                "5.15.0-86-generic",
+			"feature.node.kubernetes.io/system-os_release.ID":               "ubuntu",
+			"feature.node.kubernetes.io/system-os_release.VERSION_ID":       "22.04",
+			"feature.node.kubernetes.io/system-os_release.VERSION_ID.major": "22",
+		},
+	},
+	Status: corev1.NodeStatus{
+		NodeInfo: corev1.NodeSystemInfo{
+			ContainerRuntimeVersion: "containerd://1.7.0",
+		},
+	},
+}
+
+// LoadClusterPolicy reads a ClusterPolicy from file. If file is '-' it is read from STDIN
+func LoadClusterPolicy(file string) (*v1.ClusterPolicy, error) {
+	contents, err := readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	cp := &v1.ClusterPolicy{}
+	err = yaml.Unmarshal(contents, cp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clusterpolicy: %v", err)
+	}
+	if cp.Name == "" {
+		cp.Name = "cluster-policy"
+	}
+	return cp, nil
+}
+
+// LoadNodes reads the nodes of a cluster from file. The file holds either a list
+// of nodes or nodes as multiple yaml documents. If file is empty, a single GPU
+// node is returned
+func LoadNodes(file string) ([]corev1.Node, error) {
+	if file == "" {
+		return []corev1.Node{*defaultNode.DeepCopy()}, nil
+	}
+
+	contents, err := readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	var nodes []corev1.Node
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(contents)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read yaml document: %v", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal yaml document: %v", err)
+		}
+		switch typeMeta.Kind {
+		case "Node":
+			node := corev1.Node{}
+			if err := yaml.Unmarshal(doc, &node); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node: %v", err)
+			}
+			nodes = append(nodes, node)
+		case "NodeList", "List":
+			list := corev1.NodeList{}
+			if err := yaml.Unmarshal(doc, &list); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal node list: %v", err)
+			}
+			nodes = append(nodes, list.Items...)
+		default:
+			return nil, fmt.Errorf("unexpected kind %q, expected Node, NodeList or List", typeMeta.Kind)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found in %s", file)
+	}
+	return nodes, nil
+}
+
+func readFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(file)
+}
@@ -0,0 +1,77 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package render
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+)
+
+// logSink forwards the log messages of the operator states to the logrus
+// logger of the CLI, at debug level
+type logSink struct {
+	logger *logrus.Logger
+	name   string
+	values []interface{}
+}
+
+var _ logr.LogSink = (*logSink)(nil)
+
+// NewLogSink returns a logr.LogSink writing to logger at debug level
+func NewLogSink(logger *logrus.Logger) logr.LogSink {
+	return &logSink{logger: logger}
+}
+
+func (l *logSink) Init(info logr.RuntimeInfo) {}
+
+func (l *logSink) Enabled(level int) bool {
+	return l.logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+func (l *logSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	l.entry(keysAndValues).Debug(msg)
+}
+
+func (l *logSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.entry(keysAndValues).WithError(err).Debug(msg)
+}
+
+func (l *logSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	values := append(append([]interface{}{}, l.values...), keysAndValues...)
+	return &logSink{logger: l.logger, name: l.name, values: values}
+}
+
+func (l *logSink) WithName(name string) logr.LogSink {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &logSink{logger: l.logger, name: name, values: l.values}
+}
+
+func (l *logSink) entry(keysAndValues []interface{}) *logrus.Entry {
+	fields := logrus.Fields{}
+	if l.name != "" {
+		fields["logger"] = l.name
+	}
+	kvs := append(append([]interface{}{}, l.values...), keysAndValues...)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fields[fmt.Sprint(kvs[i])] = kvs[i+1]
+	}
+	return l.logger.WithFields(fields)
+}
@@ -0,0 +1,172 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+// Options holds the inputs needed to render the operand objects of a ClusterPolicy
+type Options struct {
+	ClusterPolicy     string
+	AssetsDir         string
+	Nodes             string
+	Namespace         string
+	KubernetesVersion string
+}
+
+// NewCommand constructs a render command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := Options{}
+
+	// Create the 'render' command
+	c := cli.Command{
+		Name:  "render",
+		Usage: "Render the operand objects a clusterpolicy produces, without a cluster",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = Flags(&opts)
+
+	return &c
+}
+
+// Flags returns the CLI flags configuring the rendering of a ClusterPolicy
+func Flags(opts *Options) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "clusterpolicy",
+			Usage:       "Specify the file containing the clusterpolicy yaml. If this is '-' the file is read from STDIN",
+			Value:       "-",
+			Destination: &opts.ClusterPolicy,
+		},
+		&cli.StringFlag{
+			Name:        "assets",
+			Usage:       "Specify the directory containing the assets of all states. The assets embedded in gpuop-cfg are used if unset",
+			Destination: &opts.AssetsDir,
+		},
+		&cli.StringFlag{
+			Name:        "nodes",
+			Usage:       "Specify a file containing the nodes of the cluster, as a list or as multiple yaml documents. A single GPU node running containerd is assumed if unset",
+			Destination: &opts.Nodes,
+		},
+		&cli.StringFlag{
+			Name:        "namespace",
+			Usage:       "Specify the namespace the GPU Operator is installed in",
+			Value:       "gpu-operator",
+			Destination: &opts.Namespace,
+		},
+		&cli.StringFlag{
+			Name:        "kubernetes-version",
+			Usage:       "Specify the Kubernetes version of the cluster",
+			Value:       "v1.27.2",
+			Destination: &opts.KubernetesVersion,
+		},
+	}
+}
+
+func (m command) validateFlags(c *cli.Context, opts *Options) error {
+	return opts.Validate()
+}
+
+// Validate checks the rendering options for missing values
+func (o Options) Validate() error {
+	if o.Namespace == "" {
+		return fmt.Errorf("the namespace must be specified")
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *Options) error {
+	result, err := opts.Render(c.Context, m.logger)
+	if err != nil {
+		return err
+	}
+
+	return WriteObjects(c.App.Writer, result.Objects)
+}
+
+// Render loads the ClusterPolicy and the nodes and renders the operand objects
+func (o Options) Render(ctx context.Context, logger *logrus.Logger) (*controllers.RenderResult, error) {
+	cp, err := LoadClusterPolicy(o.ClusterPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clusterpolicy: %v", err)
+	}
+
+	nodes, err := LoadNodes(o.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %v", err)
+	}
+
+	renderOpts := controllers.RenderOptions{
+		AssetsDir:         o.AssetsDir,
+		Namespace:         o.Namespace,
+		KubernetesVersion: o.KubernetesVersion,
+		Nodes:             nodes,
+	}
+	if logger.IsLevelEnabled(logrus.DebugLevel) {
+		renderOpts.Log = logr.New(NewLogSink(logger))
+	}
+
+	result, err := controllers.Render(ctx, cp, renderOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render clusterpolicy: %v", err)
+	}
+	return result, nil
+}
+
+// WriteObjects writes objs as a stream of yaml documents
+func WriteObjects(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %v", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		_, err = fmt.Fprintf(w, "---\n%s", data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	gpuPresentLabelKey     = "xdxct.com/gpu.present"
+	deployLabelPrefix      = "xdxct.com/gpu.deploy."
+	workloadConfigLabelKey = "xdxct.com/gpu.workload.config"
+	kernelLabelKey         = "feature.node.kubernetes.io/kernel-version.full"
+	osReleaseIDLabelKey    = "feature.node.kubernetes.io/system-os_release.ID"
+	osVersionIDLabelKey    = "feature.node.kubernetes.io/system-os_release.VERSION_ID"
+	// upgradeStateLabelKey is the label the driver upgrade controller records the upgrade state of a node in
+	upgradeStateLabelKey = "xdxct.com/gpu-driver-upgrade-state"
+)
+
+// nodeStatus summarizes the GPU Operator state of a GPU node
+type nodeStatus struct {
+	Name           string `json:"name"`
+	WorkloadConfig string `json:"workloadConfig,omitempty"`
+	// DeployLabels maps the operands to the value of their xdxct.com/gpu.deploy.<operand> label
+	DeployLabels map[string]string `json:"deployLabels,omitempty"`
+	UpgradeState string            `json:"upgradeState,omitempty"`
+	OSTag        string            `json:"osTag,omitempty"`
+	Kernel       string            `json:"kernel,omitempty"`
+	Operands     []operandStatus   `json:"operands"`
+}
+
+// operandStatus is the state of the pod of an operand on a node
+type operandStatus struct {
+	Name     string `json:"name"`
+	Pod      string `json:"pod"`
+	State    string `json:"state"`
+	Restarts int32  `json:"restarts"`
+}
+
+// getNodeStatuses returns the status of the GPU nodes, sorted by name. If nodes
+// is not empty, only the given nodes are returned
+func getNodeStatuses(ctx context.Context, clientset kubernetes.Interface, namespace string, nodes []string) ([]nodeStatus, error) {
+	list, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	selected := map[string]bool{}
+	for _, name := range nodes {
+		selected[name] = true
+	}
+
+	statuses := map[string]*nodeStatus{}
+	for _, node := range list.Items {
+		if len(selected) != 0 && !selected[node.Name] {
+			continue
+		}
+		if len(selected) == 0 && !isGPUNode(node) {
+			continue
+		}
+		statuses[node.Name] = newNodeStatus(node)
+	}
+	for _, name := range nodes {
+		if statuses[name] == nil {
+			return nil, fmt.Errorf("node %s not found", name)
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for _, pod := range pods.Items {
+		status, ok := statuses[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		operand := operandName(pod)
+		if operand == "" {
+			continue
+		}
+		status.Operands = append(status.Operands, operandStatus{
+			Name:     operand,
+			Pod:      pod.Name,
+			State:    podState(pod),
+			Restarts: podRestarts(pod),
+		})
+	}
+
+	result := make([]nodeStatus, 0, len(statuses))
+	for _, status := range statuses {
+		sort.Slice(status.Operands, func(i, j int) bool {
+			return status.Operands[i].Name < status.Operands[j].Name
+		})
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// isGPUNode returns true if the operator labelled node as a GPU node, or scheduled operands on it
+func isGPUNode(node corev1.Node) bool {
+	if node.Labels[gpuPresentLabelKey] == "true" {
+		return true
+	}
+	for key := range node.Labels {
+		if strings.HasPrefix(key, deployLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func newNodeStatus(node corev1.Node) *nodeStatus {
+	status := &nodeStatus{
+		Name:           node.Name,
+		WorkloadConfig: node.Labels[workloadConfigLabelKey],
+		UpgradeState:   node.Labels[upgradeStateLabelKey],
+		Kernel:         node.Labels[kernelLabelKey],
+		Operands:       []operandStatus{},
+	}
+	// the operator appends the OS tag to the image of the driver, e.g. ubuntu22.04
+	if node.Labels[osReleaseIDLabelKey] != "" {
+		status.OSTag = node.Labels[osReleaseIDLabelKey] + node.Labels[osVersionIDLabelKey]
+	}
+	for key, value := range node.Labels {
+		if strings.HasPrefix(key, deployLabelPrefix) {
+			if status.DeployLabels == nil {
+				status.DeployLabels = map[string]string{}
+			}
+			status.DeployLabels[strings.TrimPrefix(key, deployLabelPrefix)] = value
+		}
+	}
+	return status
+}
+
+// operandName returns the name of the operand a pod belongs to, derived from the
+// name of its DaemonSet, e.g. device-plugin for xdxct-device-plugin-daemonset.
+// An empty string is returned for pods which are not part of a DaemonSet
+func operandName(pod corev1.Pod) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind != "DaemonSet" {
+			continue
+		}
+		name := strings.TrimSuffix(owner.Name, "-daemonset")
+		for _, prefix := range []string{"xdxct-", "nvidia-"} {
+			name = strings.TrimPrefix(name, prefix)
+		}
+		return name
+	}
+	return ""
+}
+
+// podState returns the state of pod the way kubectl reports it, e.g. Running,
+// CrashLoopBackOff, ImagePullBackOff or Init:ErrImagePull. Running pods whose
+// containers are not ready are reported as NotReady
+func podState(pod corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+
+	for _, status := range pod.Status.InitContainerStatuses {
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+			continue
+		case status.State.Terminated != nil:
+			return "Init:" + reasonOr(status.State.Terminated.Reason, "Error")
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			return "Init:" + status.State.Waiting.Reason
+		default:
+			return "Init:Running"
+		}
+	}
+
+	ready := len(pod.Status.ContainerStatuses) != 0
+	for _, status := range pod.Status.ContainerStatuses {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			return status.State.Waiting.Reason
+		case status.State.Terminated != nil:
+			return reasonOr(status.State.Terminated.Reason, "Terminated")
+		}
+		ready = ready && status.Ready
+	}
+
+	if pod.Status.Phase == corev1.PodRunning && !ready {
+		return "NotReady"
+	}
+	return reasonOr(pod.Status.Reason, string(pod.Status.Phase))
+}
+
+func reasonOr(reason string, fallback string) string {
+	if reason == "" {
+		return fallback
+	}
+	return reason
+}
+
+func podRestarts(pod corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
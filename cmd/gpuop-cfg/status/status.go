@@ -0,0 +1,223 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	namespace  string
+	kubeconfig string
+	nodes      cli.StringSlice
+	output     string
+}
+
+// NewCommand constructs a status command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'status' command
+	c := cli.Command{
+		Name:  "status",
+		Usage: "Show the labels and the state of the operands of every GPU node",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "namespace",
+			Usage:       "Specify the namespace the GPU Operator is installed in",
+			Value:       "gpu-operator",
+			Destination: &opts.namespace,
+		},
+		&cli.StringFlag{
+			Name:        "kubeconfig",
+			Usage:       "Specify the kubeconfig file of the cluster. The default loading rules of kubectl apply if unset",
+			Destination: &opts.kubeconfig,
+			EnvVars:     []string{"KUBECONFIG"},
+		},
+		&cli.StringSliceFlag{
+			Name:        "node",
+			Usage:       "Show the given node only. May be repeated, all GPU nodes are shown if unset",
+			Destination: &opts.nodes,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the output format, one of [table, json]",
+			Value:       outputTable,
+			Destination: &opts.output,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	switch opts.output {
+	case outputTable, outputJSON:
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of [%s, %s]", opts.output, outputTable, outputJSON)
+	}
+	if opts.namespace == "" {
+		return fmt.Errorf("the namespace must be specified")
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: opts.kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %v", err)
+	}
+
+	statuses, err := getNodeStatuses(c.Context, clientset, opts.namespace, opts.nodes.Value())
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		m.logger.Warnf("No GPU nodes found")
+	}
+
+	if opts.output == outputJSON {
+		return writeJSON(c.App.Writer, statuses)
+	}
+	return writeTable(c.App.Writer, statuses)
+}
+
+func writeJSON(w io.Writer, statuses []nodeStatus) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(statuses)
+}
+
+// writeTable writes a row per node, with a column per operand found on any of the nodes
+func writeTable(w io.Writer, statuses []nodeStatus) error {
+	operands := map[string]bool{}
+	for _, status := range statuses {
+		for _, operand := range status.Operands {
+			operands[operand.Name] = true
+		}
+	}
+	columns := make([]string, 0, len(operands))
+	for operand := range operands {
+		columns = append(columns, operand)
+	}
+	sort.Strings(columns)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"NODE", "WORKLOAD", "DEPLOY", "UPGRADE", "OS", "KERNEL"}
+	for _, column := range columns {
+		header = append(header, strings.ToUpper(column))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, status := range statuses {
+		row := []string{
+			status.Name,
+			orNone(status.WorkloadConfig),
+			orNone(formatDeployLabels(status.DeployLabels)),
+			orNone(status.UpgradeState),
+			orNone(status.OSTag),
+			orNone(status.Kernel),
+		}
+		for _, column := range columns {
+			var states []string
+			for _, operand := range status.Operands {
+				if operand.Name != column {
+					continue
+				}
+				state := operand.State
+				if operand.Restarts != 0 {
+					state = fmt.Sprintf("%s(%d)", state, operand.Restarts)
+				}
+				states = append(states, state)
+			}
+			row = append(row, orNone(strings.Join(states, ",")))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// formatDeployLabels lists the operands deployed to a node. Operands whose
+// label is not "true" are listed with their value, e.g. driver=false
+func formatDeployLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	formatted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if labels[key] == "true" {
+			formatted = append(formatted, key)
+			continue
+		}
+		formatted = append(formatted, key+"="+labels[key])
+	}
+	return strings.Join(formatted, ",")
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
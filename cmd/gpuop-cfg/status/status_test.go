@@ -0,0 +1,165 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPod(name string, node string, daemonset string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "gpu-operator",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: daemonset}},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: statuses,
+		},
+	}
+}
+
+func running(ready bool) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: "main", Ready: ready, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+}
+
+func waiting(reason string, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: "main", RestartCount: restarts, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+}
+
+func TestNodeStatus(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name: "gpu-a",
+			Labels: map[string]string{
+				"xdxct.com/gpu.present":                                   "true",
+				"xdxct.com/gpu.workload.config":                           "container",
+				"xdxct.com/gpu.deploy.container-toolkit":                  "true",
+				"xdxct.com/gpu.deploy.device-plugin":                      "false",
+				"xdxct.com/gpu-driver-upgrade-state":                      "upgrade-done",
+				"feature.node.kubernetes.io/kernel-version.full":          "5.15.0-86-generic",
+				"feature.node.kubernetes.io/system-os_release.ID":         "ubuntu",
+				"feature.node.kubernetes.io/system-os_release.VERSION_ID": "22.04",
+			},
+		}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "gpu-b",
+			Labels: map[string]string{"xdxct.com/gpu.deploy.container-toolkit": "true"},
+		}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}},
+		newTestPod("toolkit-a", "gpu-a", "xdxct-container-toolkit-daemonset", running(true)),
+		newTestPod("plugin-a", "gpu-a", "xdxct-device-plugin-daemonset", waiting("CrashLoopBackOff", 4)),
+		newTestPod("toolkit-b", "gpu-b", "xdxct-container-toolkit-daemonset", waiting("ImagePullBackOff", 0)),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-operator", Namespace: "gpu-operator"},
+			Spec:       corev1.PodSpec{NodeName: "gpu-a"},
+		},
+	)
+
+	statuses, err := getNodeStatuses(context.TODO(), clientset, "gpu-operator", nil)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	require.Equal(t, nodeStatus{
+		Name:           "gpu-a",
+		WorkloadConfig: "container",
+		DeployLabels:   map[string]string{"container-toolkit": "true", "device-plugin": "false"},
+		UpgradeState:   "upgrade-done",
+		OSTag:          "ubuntu22.04",
+		Kernel:         "5.15.0-86-generic",
+		Operands: []operandStatus{
+			{Name: "container-toolkit", Pod: "toolkit-a", State: "Running"},
+			{Name: "device-plugin", Pod: "plugin-a", State: "CrashLoopBackOff", Restarts: 4},
+		},
+	}, statuses[0])
+	require.Equal(t, "gpu-b", statuses[1].Name)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTable(&buf, statuses))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"NODE", "WORKLOAD", "DEPLOY", "UPGRADE", "OS", "KERNEL", "CONTAINER-TOOLKIT", "DEVICE-PLUGIN"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"gpu-a", "container", "container-toolkit,device-plugin=false", "upgrade-done", "ubuntu22.04", "5.15.0-86-generic", "Running", "CrashLoopBackOff(4)"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"gpu-b", "-", "container-toolkit", "-", "-", "-", "ImagePullBackOff", "-"}, strings.Fields(lines[2]))
+
+	statuses, err = getNodeStatuses(context.TODO(), clientset, "gpu-operator", []string{"cpu"})
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	require.Empty(t, statuses[0].Operands)
+
+	_, err = getNodeStatuses(context.TODO(), clientset, "gpu-operator", []string{"missing"})
+	require.Error(t, err)
+}
+
+func TestPodState(t *testing.T) {
+	testCases := []struct {
+		description string
+		pod         *corev1.Pod
+		expected    string
+	}{
+		{
+			description: "running and ready",
+			pod:         newTestPod("p", "n", "ds", running(true)),
+			expected:    "Running",
+		},
+		{
+			description: "running, not ready",
+			pod:         newTestPod("p", "n", "ds", running(true), running(false)),
+			expected:    "NotReady",
+		},
+		{
+			description: "image pull error",
+			pod:         newTestPod("p", "n", "ds", waiting("ErrImagePull", 0)),
+			expected:    "ErrImagePull",
+		},
+		{
+			description: "init container crashing",
+			pod: func() *corev1.Pod {
+				pod := newTestPod("p", "n", "ds", waiting("PodInitializing", 0))
+				pod.Status.Phase = corev1.PodPending
+				pod.Status.InitContainerStatuses = []corev1.ContainerStatus{waiting("CrashLoopBackOff", 2)}
+				return pod
+			}(),
+			expected: "Init:CrashLoopBackOff",
+		},
+		{
+			description: "pending",
+			pod: func() *corev1.Pod {
+				pod := newTestPod("p", "n", "ds")
+				pod.Status.Phase = corev1.PodPending
+				return pod
+			}(),
+			expected: "Pending",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, podState(*tc.pod))
+		})
+	}
+}
@@ -0,0 +1,411 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+)
+
+const (
+	// validationsDir is the directory the validator writes its status files to
+	validationsDir = "/run/nvidia/validations"
+	// errorsFile lists the artefacts which could not be collected
+	errorsFile = "errors.txt"
+)
+
+// nodeLabelPrefixes select the node labels collected in the bundle
+var nodeLabelPrefixes = []string{
+	"xdxct.com/",
+	"feature.node.kubernetes.io/",
+}
+
+// podExecutor runs a command in a container and returns its output
+type podExecutor interface {
+	Exec(ctx context.Context, namespace, pod, container string, command []string) ([]byte, error)
+}
+
+// collector gathers the diagnostics of the GPU Operator into a bundle
+type collector struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	// executor reads the validator status files, they are not collected if unset
+	executor  podExecutor
+	logger    *logrus.Logger
+	namespace string
+	// nodes limits the node specific artefacts to the given nodes, all nodes are collected if empty
+	nodes map[string]bool
+
+	bundle *bundleWriter
+	errs   []string
+}
+
+// bundleWriter writes the files of the bundle to a gzipped tarball
+type bundleWriter struct {
+	root string
+	now  time.Time
+	gz   *gzip.Writer
+	tw   *tar.Writer
+}
+
+func newBundleWriter(w io.Writer, root string) *bundleWriter {
+	gz := gzip.NewWriter(w)
+	return &bundleWriter{root: root, now: time.Now(), gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (b *bundleWriter) add(name string, data []byte) error {
+	err := b.tw.WriteHeader(&tar.Header{
+		Name:    path.Join(b.root, name),
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = b.tw.Write(data)
+	return err
+}
+
+func (b *bundleWriter) close() error {
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
+
+// collect writes the bundle to w, as a gzipped tarball whose files are placed under root.
+// Artefacts which cannot be collected are listed in errors.txt instead of failing the collection
+func (c *collector) collect(ctx context.Context, w io.Writer, root string) error {
+	c.bundle = newBundleWriter(w, root)
+
+	c.collectClusterPolicies(ctx)
+	c.collectNodes(ctx)
+	c.collectDaemonSets(ctx)
+	pods := c.collectPods(ctx)
+	c.collectLogs(ctx, pods)
+	c.collectEvents(ctx, pods)
+	c.collectValidations(ctx, pods)
+
+	if len(c.errs) != 0 {
+		if err := c.bundle.add(errorsFile, []byte(strings.Join(c.errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return c.bundle.close()
+}
+
+// recordError adds err to the errors of the bundle
+func (c *collector) recordError(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	c.logger.Warn(msg)
+	c.errs = append(c.errs, msg)
+}
+
+// add writes a redacted file to the bundle
+func (c *collector) add(name string, data []byte) {
+	if err := c.bundle.add(name, redactText(data)); err != nil {
+		c.recordError("failed to write %s: %v", name, err)
+	}
+}
+
+// addYAML writes obj to the bundle as yaml
+func (c *collector) addYAML(name string, obj interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		c.recordError("failed to marshal %s: %v", name, err)
+		return
+	}
+	c.add(name, data)
+}
+
+func (c *collector) selectedNode(name string) bool {
+	return len(c.nodes) == 0 || c.nodes[name]
+}
+
+func (c *collector) collectClusterPolicies(ctx context.Context) {
+	gvr := v1.GroupVersion.WithResource("clusterpolicies")
+	list, err := c.dynamic.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.recordError("failed to list clusterpolicies: %v", err)
+		return
+	}
+	for i := range list.Items {
+		unstructured.RemoveNestedField(list.Items[i].Object, "metadata", "managedFields")
+		redactClusterPolicy(&list.Items[i])
+	}
+	c.addYAML("clusterpolicies.yaml", list)
+}
+
+// collectNodes writes the GPU Operator and NFD labels as well as the GPU resources of the nodes
+func (c *collector) collectNodes(ctx context.Context) {
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.recordError("failed to list nodes: %v", err)
+		return
+	}
+
+	found := map[string]bool{}
+	for _, node := range nodes.Items {
+		if !c.selectedNode(node.Name) {
+			continue
+		}
+		found[node.Name] = true
+
+		labels := map[string]string{}
+		for k, v := range node.Labels {
+			for _, prefix := range nodeLabelPrefixes {
+				if strings.HasPrefix(k, prefix) {
+					labels[k] = v
+					break
+				}
+			}
+		}
+		c.addYAML(path.Join("nodes", node.Name, "node.yaml"), map[string]interface{}{
+			"name":        node.Name,
+			"labels":      labels,
+			"capacity":    node.Status.Capacity,
+			"allocatable": node.Status.Allocatable,
+			"conditions":  node.Status.Conditions,
+			"nodeInfo":    node.Status.NodeInfo,
+		})
+	}
+
+	for _, name := range sortedKeys(c.nodes) {
+		if !found[name] {
+			c.recordError("node %s not found", name)
+		}
+	}
+}
+
+func (c *collector) collectDaemonSets(ctx context.Context) {
+	list, err := c.clientset.AppsV1().DaemonSets(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.recordError("failed to list daemonsets: %v", err)
+		return
+	}
+	list.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSetList"}
+	for i := range list.Items {
+		list.Items[i].ManagedFields = nil
+		redactPodSpec(&list.Items[i].Spec.Template.Spec)
+	}
+	c.addYAML(path.Join(c.namespace, "daemonsets.yaml"), list)
+}
+
+// collectPods writes the pods of the operator namespace. If the collection is
+// limited to some nodes, pods of DaemonSets running on other nodes are skipped
+func (c *collector) collectPods(ctx context.Context) []corev1.Pod {
+	list, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.recordError("failed to list pods: %v", err)
+		return nil
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		if !c.selectedNode(pod.Spec.NodeName) && isDaemonSetPod(pod) {
+			continue
+		}
+		pod.ManagedFields = nil
+		redactPodSpec(&pod.Spec)
+		pods = append(pods, pod)
+	}
+	c.addYAML(path.Join(c.namespace, "pods.yaml"), &corev1.PodList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PodList"},
+		Items:    pods,
+	})
+	return pods
+}
+
+func isDaemonSetPod(pod corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// collectLogs writes the logs of all containers of pods, including the logs of
+// the previous instance of the containers which restarted
+func (c *collector) collectLogs(ctx context.Context, pods []corev1.Pod) {
+	for _, pod := range pods {
+		statuses := map[string]corev1.ContainerStatus{}
+		for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			statuses[status.Name] = status
+		}
+
+		for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			dir := path.Join(c.namespace, "pods", pod.Name)
+			c.collectLog(ctx, pod, container.Name, false, path.Join(dir, container.Name+".log"))
+			if statuses[container.Name].RestartCount > 0 {
+				c.collectLog(ctx, pod, container.Name, true, path.Join(dir, container.Name+".previous.log"))
+			}
+		}
+	}
+}
+
+func (c *collector) collectLog(ctx context.Context, pod corev1.Pod, container string, previous bool, name string) {
+	stream, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	}).Stream(ctx)
+	if err != nil {
+		c.recordError("failed to get logs of container %s of pod %s: %v", container, pod.Name, err)
+		return
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		c.recordError("failed to read logs of container %s of pod %s: %v", container, pod.Name, err)
+		return
+	}
+	c.add(name, data)
+}
+
+// collectEvents writes the events of the operator namespace and of the nodes. If the
+// collection is limited to some nodes, only the events of the collected pods, the
+// DaemonSets and the selected nodes are written
+func (c *collector) collectEvents(ctx context.Context, pods []corev1.Pod) {
+	collectedPods := map[string]bool{}
+	for _, pod := range pods {
+		collectedPods[pod.Name] = true
+	}
+
+	list, err := c.clientset.CoreV1().Events(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.recordError("failed to list events: %v", err)
+		return
+	}
+	var events []corev1.Event
+	for _, event := range list.Items {
+		if len(c.nodes) != 0 && event.InvolvedObject.Kind == "Pod" && !collectedPods[event.InvolvedObject.Name] {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	nodeEvents, err := c.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "Node").String(),
+	})
+	if err != nil {
+		c.recordError("failed to list node events: %v", err)
+	} else {
+		for _, event := range nodeEvents.Items {
+			if event.InvolvedObject.Kind == "Node" && c.selectedNode(event.InvolvedObject.Name) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	for i := range events {
+		events[i].ManagedFields = nil
+	}
+	c.addYAML("events.yaml", &corev1.EventList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "EventList"},
+		Items:    events,
+	})
+}
+
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// collectValidations writes the validator status files of every node. They are read
+// from the first running container per node which mounts the validations directory
+func (c *collector) collectValidations(ctx context.Context, pods []corev1.Pod) {
+	if c.executor == nil {
+		return
+	}
+
+	script := fmt.Sprintf(`for f in %s/*; do [ -f "$f" ] || continue; echo "==> $f <=="; cat "$f"; done`, validationsDir)
+	collected := map[string]bool{}
+	for _, pod := range pods {
+		node := pod.Spec.NodeName
+		if node == "" || collected[node] || !c.selectedNode(node) || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		container := validationsContainer(pod)
+		if container == "" {
+			continue
+		}
+
+		out, err := c.executor.Exec(ctx, pod.Namespace, pod.Name, container, []string{"sh", "-c", script})
+		if err != nil {
+			c.recordError("failed to read validations of node %s from pod %s: %v", node, pod.Name, err)
+			continue
+		}
+		collected[node] = true
+		c.add(path.Join("nodes", node, "validations.txt"), out)
+	}
+}
+
+// validationsContainer returns the first running container of pod which mounts the validations directory
+func validationsContainer(pod corev1.Pod) string {
+	running := map[string]bool{}
+	for _, status := range pod.Status.ContainerStatuses {
+		running[status.Name] = status.State.Running != nil
+	}
+	for _, container := range pod.Spec.Containers {
+		if !running[container.Name] {
+			continue
+		}
+		for _, mount := range container.VolumeMounts {
+			if mount.MountPath == validationsDir {
+				return container.Name
+			}
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
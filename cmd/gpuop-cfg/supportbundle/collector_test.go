@@ -0,0 +1,263 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+)
+
+const testNamespace = "gpu-operator"
+
+// fakeExecutor returns the validator status files of the node a pod runs on
+type fakeExecutor struct {
+	pods map[string]string
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, namespace, pod, container string, command []string) ([]byte, error) {
+	node, ok := e.pods[pod]
+	if !ok {
+		return nil, errors.New("unexpected pod")
+	}
+	return []byte("==> " + validationsDir + "/toolkit-ready <==\n" + node + "\n"), nil
+}
+
+func newTestNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"xdxct.com/gpu.present":                       "true",
+				"feature.node.kubernetes.io/pci-10de.present": "true",
+				"kubernetes.io/hostname":                      name,
+			},
+		},
+	}
+}
+
+func newTestPod(name string, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       testNamespace,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "xdxct-container-toolkit-daemonset"}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name: "toolkit",
+				Env: []corev1.EnvVar{
+					{Name: "REGISTRY_PASSWORD", Value: "hunter2"},
+					{Name: "RUNTIME", Value: "containerd"},
+				},
+				Args:         []string{"--token=abcdef"},
+				VolumeMounts: []corev1.VolumeMount{{Name: "run-nvidia-validations", MountPath: validationsDir}},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "toolkit",
+				RestartCount: 1,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+func newTestEvent(name string, kind string, object string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object},
+		Message:        name,
+	}
+}
+
+// readBundle returns the files of a gzipped tarball by their name
+func readBundle(t *testing.T, data []byte) map[string]string {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		contents, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(contents)
+	}
+	return files
+}
+
+func TestSupportBundle(t *testing.T) {
+	operatorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-operator-6f8b9", Namespace: testNamespace},
+		Spec: corev1.PodSpec{
+			NodeName:   "control-plane",
+			Containers: []corev1.Container{{Name: "gpu-operator"}},
+		},
+	}
+	objects := []runtime.Object{
+		newTestNode("node-a"),
+		newTestNode("node-b"),
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "xdxct-container-toolkit-daemonset", Namespace: testNamespace}},
+		operatorPod,
+		newTestPod("toolkit-a", "node-a"),
+		newTestPod("toolkit-b", "node-b"),
+		newTestEvent("event-a", "Pod", "toolkit-a"),
+		newTestEvent("event-b", "Pod", "toolkit-b"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: testNamespace}, Data: map[string][]byte{"password": []byte("hunter2")}},
+	}
+
+	cp := &unstructured.Unstructured{}
+	cp.SetAPIVersion(v1.GroupVersion.String())
+	cp.SetKind("ClusterPolicy")
+	cp.SetName("cluster-policy")
+	cp.SetAnnotations(map[string]string{
+		corev1.LastAppliedConfigAnnotation: `{"spec":{"driver":{"licensingConfig":{"nlsToken":"lic-3f9a"}}}}`,
+	})
+	// the name and the value of the env variable are on separate lines of the bundle
+	require.NoError(t, unstructured.SetNestedSlice(cp.Object, []interface{}{
+		map[string]interface{}{"name": "LICENSE_TOKEN", "value": "lic-3f9a"},
+		map[string]interface{}{"name": "DRIVER_FLAVOR", "value": "open"},
+	}, "spec", "driver", "env"))
+
+	testCases := []struct {
+		description string
+		nodes       []string
+		expected    []string
+		unexpected  []string
+		errors      bool
+	}{
+		{
+			description: "all nodes",
+			expected: []string{
+				"clusterpolicies.yaml",
+				"events.yaml",
+				"nodes/node-a/node.yaml",
+				"nodes/node-b/node.yaml",
+				"nodes/node-a/validations.txt",
+				"nodes/node-b/validations.txt",
+				"gpu-operator/daemonsets.yaml",
+				"gpu-operator/pods.yaml",
+				"gpu-operator/pods/gpu-operator-6f8b9/gpu-operator.log",
+				"gpu-operator/pods/toolkit-a/toolkit.log",
+				"gpu-operator/pods/toolkit-a/toolkit.previous.log",
+				"gpu-operator/pods/toolkit-b/toolkit.log",
+			},
+			unexpected: []string{errorsFile},
+		},
+		{
+			description: "limited to a node",
+			nodes:       []string{"node-a"},
+			expected: []string{
+				"nodes/node-a/node.yaml",
+				"nodes/node-a/validations.txt",
+				"gpu-operator/pods/gpu-operator-6f8b9/gpu-operator.log",
+				"gpu-operator/pods/toolkit-a/toolkit.log",
+			},
+			unexpected: []string{
+				"nodes/node-b/node.yaml",
+				"nodes/node-b/validations.txt",
+				"gpu-operator/pods/toolkit-b/toolkit.log",
+				errorsFile,
+			},
+		},
+		{
+			description: "unknown node",
+			nodes:       []string{"node-c"},
+			expected:    []string{errorsFile},
+			unexpected:  []string{"nodes/node-a/node.yaml"},
+			errors:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(objects...)
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{v1.GroupVersion.WithResource("clusterpolicies"): "ClusterPolicyList"}, cp)
+
+			c := &collector{
+				clientset: clientset,
+				dynamic:   dynamicClient,
+				executor:  &fakeExecutor{pods: map[string]string{"toolkit-a": "node-a", "toolkit-b": "node-b"}},
+				logger:    logrus.New(),
+				namespace: testNamespace,
+				nodes:     map[string]bool{},
+			}
+			for _, node := range tc.nodes {
+				c.nodes[node] = true
+			}
+
+			var buf bytes.Buffer
+			err := c.collect(context.TODO(), &buf, "bundle")
+			require.NoError(t, err)
+			require.Equal(t, tc.errors, len(c.errs) != 0, "errors: %v", c.errs)
+
+			files := readBundle(t, buf.Bytes())
+			for _, name := range tc.expected {
+				require.Contains(t, files, path.Join("bundle", name))
+			}
+			for _, name := range tc.unexpected {
+				require.NotContains(t, files, path.Join("bundle", name))
+			}
+
+			policies := files["bundle/clusterpolicies.yaml"]
+			require.NotContains(t, policies, "lic-3f9a")
+			require.NotContains(t, policies, corev1.LastAppliedConfigAnnotation)
+			require.Contains(t, policies, "LICENSE_TOKEN")
+			require.Contains(t, policies, "open")
+
+			pods := files["bundle/gpu-operator/pods.yaml"]
+			require.NotContains(t, pods, "hunter2")
+			require.NotContains(t, pods, "abcdef")
+			if !tc.errors {
+				require.Contains(t, pods, "containerd")
+			}
+			for name, contents := range files {
+				require.NotContains(t, contents, "pull-secret", "secret found in %s", name)
+			}
+			if len(tc.nodes) != 0 {
+				require.NotContains(t, files["bundle/events.yaml"], "event-b")
+			}
+		})
+	}
+}
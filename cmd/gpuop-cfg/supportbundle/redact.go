@@ -0,0 +1,97 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package supportbundle
+
+import (
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveName matches names of env variables and keys which hold secrets
+var sensitiveName = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)`)
+
+// sensitiveAssignment matches secrets assigned in text, e.g. in logs or command lines:
+// "password=...", "token: ..." or "--api-key=..."
+var sensitiveAssignment = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)["']?\s*[:=]\s*["']?)([^\s"',;]+)`)
+
+// bearerToken matches the bearer tokens of HTTP Authorization headers
+var bearerToken = regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9\-._~+/]+=*`)
+
+// redactText replaces the secrets found in data
+func redactText(data []byte) []byte {
+	data = bearerToken.ReplaceAll(data, []byte("${1}"+redacted))
+	return sensitiveAssignment.ReplaceAllFunc(data, func(match []byte) []byte {
+		groups := sensitiveAssignment.FindSubmatch(match)
+		switch string(groups[2]) {
+		case redacted, "true", "false", "null":
+			return match
+		}
+		return append(append([]byte{}, groups[1]...), redacted...)
+	})
+}
+
+// redactPodSpec replaces the values of the env variables of podSpec whose name denotes a secret.
+// Env variables set from secrets only hold a reference to the secret and are kept
+func redactPodSpec(podSpec *corev1.PodSpec) {
+	redactContainers := func(containers []corev1.Container) {
+		for i := range containers {
+			for j, env := range containers[i].Env {
+				if env.Value != "" && sensitiveName.MatchString(env.Name) {
+					containers[i].Env[j].Value = redacted
+				}
+			}
+		}
+	}
+	redactContainers(podSpec.InitContainers)
+	redactContainers(podSpec.Containers)
+}
+
+// redactClusterPolicy replaces the values of the env variables of the ClusterPolicy
+// whose name denotes a secret, the name and the value of an env variable are on
+// separate lines and are not matched by redactText. The last-applied-configuration
+// annotation holds a copy of the spec and is removed
+func redactClusterPolicy(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+	redactEnv(obj.Object)
+}
+
+// redactEnv replaces the values of the env variables found in value, i.e. the maps
+// with a name denoting a secret and a value
+func redactEnv(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		name, _ := v["name"].(string)
+		if env, ok := v["value"].(string); ok && env != "" && sensitiveName.MatchString(name) {
+			v["value"] = redacted
+		}
+		for _, field := range v {
+			redactEnv(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			redactEnv(item)
+		}
+	}
+}
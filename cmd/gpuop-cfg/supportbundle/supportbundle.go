@@ -0,0 +1,166 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package supportbundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	namespace   string
+	kubeconfig  string
+	nodes       cli.StringSlice
+	output      string
+	validations bool
+}
+
+// NewCommand constructs a support-bundle command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'support-bundle' command
+	c := cli.Command{
+		Name:  "support-bundle",
+		Usage: "Collect the diagnostics of the GPU Operator into a tarball",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "namespace",
+			Usage:       "Specify the namespace the GPU Operator is installed in",
+			Value:       "gpu-operator",
+			Destination: &opts.namespace,
+		},
+		&cli.StringFlag{
+			Name:        "kubeconfig",
+			Usage:       "Specify the kubeconfig file of the cluster. The default loading rules of kubectl apply if unset",
+			Destination: &opts.kubeconfig,
+			EnvVars:     []string{"KUBECONFIG"},
+		},
+		&cli.StringSliceFlag{
+			Name:        "node",
+			Usage:       "Limit the node specific diagnostics to the given node. May be repeated, all nodes are collected if unset",
+			Destination: &opts.nodes,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the file the tarball is written to. Defaults to gpu-operator-support-bundle-<timestamp>.tar.gz",
+			Destination: &opts.output,
+		},
+		&cli.BoolFlag{
+			Name:        "validations",
+			Usage:       "Read the validator status files of the nodes by running a command in the operand pods",
+			Value:       true,
+			Destination: &opts.validations,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if opts.namespace == "" {
+		return fmt.Errorf("the namespace must be specified")
+	}
+	if opts.output == "" {
+		opts.output = fmt.Sprintf("gpu-operator-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: opts.kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+
+	collector := &collector{
+		clientset: clientset,
+		dynamic:   dynamicClient,
+		logger:    m.logger,
+		namespace: opts.namespace,
+		nodes:     map[string]bool{},
+	}
+	if opts.validations {
+		collector.executor = &remoteExecutor{config: cfg, clientset: clientset}
+	}
+	for _, node := range opts.nodes.Value() {
+		collector.nodes[node] = true
+	}
+
+	f, err := os.Create(opts.output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", opts.output, err)
+	}
+	defer f.Close()
+
+	root := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(opts.output), ".gz"), ".tar")
+	err = collector.collect(c.Context, f, root)
+	if err != nil {
+		return fmt.Errorf("failed to write support bundle: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write support bundle: %v", err)
+	}
+
+	if len(collector.errs) != 0 {
+		m.logger.Warnf("%d artefact(s) could not be collected, see %s in the bundle", len(collector.errs), errorsFile)
+	}
+	m.logger.Infof("Support bundle written to %s", opts.output)
+	return nil
+}
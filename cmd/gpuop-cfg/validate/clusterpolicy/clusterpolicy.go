@@ -17,13 +17,19 @@
 package clusterpolicy
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 
 	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/cmd/gpuop-cfg/render"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 )
 
@@ -31,8 +37,20 @@ type command struct {
 	logger *logrus.Logger
 }
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
 type options struct {
-	input string
+	input         string
+	offline       bool
+	registryDir   string
+	osTags        cli.StringSlice
+	architectures cli.StringSlice
+	nodes         string
+	crd           string
+	output        string
 }
 
 // NewCommand constructs a clusterpolicy command with the specified logger
@@ -66,41 +84,199 @@ func (m command) build() *cli.Command {
 			Value:       "-",
 			Destination: &opts.input,
 		},
+		&cli.BoolFlag{
+			Name:        "offline",
+			Usage:       "Skip the checks requiring network access. Images are only validated against a registry directory",
+			Destination: &opts.offline,
+		},
+		&cli.StringFlag{
+			Name:        "registry-dir",
+			Usage:       "Specify a directory holding an OCI image layout per repository, as <registry-dir>/<registry>/<repository>, to validate images against instead of the registry",
+			Destination: &opts.registryDir,
+		},
+		&cli.StringSliceFlag{
+			Name:        "os-tags",
+			Usage:       "Specify the OS tags appended to the driver image, one driver image is validated per OS",
+			Value:       cli.NewStringSlice("ubuntu22.04"),
+			Destination: &opts.osTags,
+		},
+		&cli.StringSliceFlag{
+			Name:        "architectures",
+			Usage:       "Specify the architectures, such as amd64 or arm64, every image must provide a manifest for",
+			Destination: &opts.architectures,
+		},
+		&cli.StringFlag{
+			Name:        "nodes",
+			Usage:       "Specify a file containing the nodes of the cluster. Every image must provide a manifest for the architectures of the nodes",
+			Destination: &opts.nodes,
+		},
+		&cli.StringFlag{
+			Name:        "crd",
+			Usage:       "Specify the file containing the ClusterPolicy CRD whose OpenAPI schema the clusterpolicy is validated against. The CRD embedded in gpuop-cfg is used if unset",
+			Destination: &opts.crd,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Specify the output format of the findings, one of [text, json]",
+			Value:       outputText,
+			Destination: &opts.output,
+		},
 	}
 
 	return &c
 }
 
 func (m command) validateFlags(c *cli.Context, opts *options) error {
+	switch opts.output {
+	case outputText, outputJSON:
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of [%s, %s]", opts.output, outputText, outputJSON)
+	}
 	return nil
 }
 
 func (m command) run(c *cli.Context, opts *options) error {
-	cp, err := opts.load()
+	contents, err := opts.getContents()
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+
+	findings, err := opts.validate(c.Context, contents)
 	if err != nil {
-		return fmt.Errorf("failed to load clusterpolicy spec: %v", err)
+		return err
 	}
 
-	err = validateImages(&cp.Spec)
+	err = writeFindings(c.App.Writer, opts.output, findings)
 	if err != nil {
-		return fmt.Errorf("failed to validate images: %v", err)
+		return err
+	}
+	if len(findings) != 0 {
+		return fmt.Errorf("clusterpolicy is invalid: %d finding(s)", len(findings))
 	}
+	m.logger.Debugf("clusterpolicy is valid")
 
 	return nil
 }
 
-func (o options) load() (*v1.ClusterPolicy, error) {
-	contents, err := o.getContents()
+// ValidateOffline runs the schema and semantic checks of 'validate clusterpolicy --offline'
+// on the clusterpolicy in contents against the CRD in the file crd, or the embedded CRD if
+// empty, and returns the findings as text
+func ValidateOffline(ctx context.Context, contents []byte, crd string) ([]string, error) {
+	o := &options{input: "-", offline: true, crd: crd}
+	findings, err := o.validate(ctx, contents)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %v", err)
+		return nil, err
+	}
+
+	messages := make([]string, 0, len(findings))
+	for _, f := range findings {
+		messages = append(messages, f.String())
+	}
+	return messages, nil
+}
+
+// validate returns all findings of the schema, semantic and image checks. Images
+// are not validated when offline, unless they are looked up in a registry directory
+func (o *options) validate(ctx context.Context, contents []byte) ([]finding, error) {
+	file := o.input
+	if file == "-" {
+		file = "<stdin>"
 	}
+	pos := newPositions(contents)
 
-	spec := &v1.ClusterPolicy{}
-	err = yaml.Unmarshal(contents, spec)
+	data, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert clusterpolicy to json: %v", err)
+	}
+	// integers are decoded as int64, as the API server does
+	obj := map[string]interface{}{}
+	err = utiljson.Unmarshal(data, &obj)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal spec: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal clusterpolicy: %v", err)
 	}
-	return spec, nil
+
+	version := v1.GroupVersion.Version
+	if apiVersion, ok := obj["apiVersion"].(string); ok {
+		if gv, err := schema.ParseGroupVersion(apiVersion); err == nil && gv.Version != "" {
+			version = gv.Version
+		}
+	}
+	crdSchema, err := loadCRDSchema(o.crd, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CRD schema: %v", err)
+	}
+
+	schemaErrs := crdSchema.validate(obj)
+	findings := newFindings(file, pos, sourceSchema, schemaErrs)
+
+	// the semantic checks run on the object as stored by the API server, with
+	// unknown fields dropped and defaults applied
+	defaulted, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal clusterpolicy: %v", err)
+	}
+	cp := &v1.ClusterPolicy{}
+	err = json.Unmarshal(defaulted, cp)
+	if err != nil {
+		// the schema findings explain why the clusterpolicy cannot be decoded
+		if len(findings) != 0 {
+			sortFindings(findings)
+			return findings, nil
+		}
+		return nil, fmt.Errorf("failed to unmarshal clusterpolicy: %v", err)
+	}
+
+	// skip the semantic errors of fields already reported by the schema checks
+	invalidFields := map[string]bool{}
+	for _, err := range schemaErrs {
+		invalidFields[err.Field] = true
+	}
+	semanticErrs := field.ErrorList{}
+	for _, err := range cp.ValidateSpec() {
+		if !invalidFields[err.Field] {
+			semanticErrs = append(semanticErrs, err)
+		}
+	}
+	findings = append(findings, newFindings(file, pos, sourceSemantic, semanticErrs)...)
+
+	sortFindings(findings)
+
+	if !o.offline || o.registryDir != "" {
+		imageOpts, err := o.imageOptions()
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, newFindings(file, pos, sourceImage, validateImages(ctx, &cp.Spec, imageOpts))...)
+	}
+
+	return findings, nil
+}
+
+func (o *options) imageOptions() (imageOptions, error) {
+	opts := imageOptions{
+		registryDir: o.registryDir,
+		osTags:      o.osTags.Value(),
+	}
+
+	architectures := map[string]bool{}
+	for _, arch := range o.architectures.Value() {
+		architectures[arch] = true
+	}
+	if o.nodes != "" {
+		nodes, err := render.LoadNodes(o.nodes)
+		if err != nil {
+			return opts, fmt.Errorf("failed to load nodes: %v", err)
+		}
+		for _, node := range nodes {
+			if arch := node.Status.NodeInfo.Architecture; arch != "" {
+				architectures[arch] = true
+			}
+		}
+	}
+	opts.architectures = sortedKeys(architectures)
+
+	return opts, nil
 }
 
 func (o options) getContents() ([]byte, error) {
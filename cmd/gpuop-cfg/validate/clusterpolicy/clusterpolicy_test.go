@@ -0,0 +1,150 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package clusterpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		description string
+		contents    string
+		expected    []string
+	}{
+		{
+			description: "valid",
+			contents: `apiVersion: xdxct.com/v1
+kind: ClusterPolicy
+metadata:
+  name: cluster-policy
+spec:
+  operator:
+    defaultRuntime: containerd
+  daemonsets: {}
+  driver:
+    enabled: true
+  toolkit: {}
+  devicePlugin: {}
+  gfd: {}
+  nodeStatusExporter: {}
+`,
+		},
+		{
+			description: "schema errors",
+			contents: `apiVersion: xdxct.com/v1
+kind: ClusterPolicy
+metadata:
+  name: cluster-policy
+spec:
+  operator:
+    defaultRuntime: containerd
+  daemonsets: {}
+  driver:
+    enabled: "yes"
+  devicePlugin:
+    unknownField: true
+  gfd: {}
+  nodeStatusExporter: {}
+`,
+			// the clusterpolicy cannot be decoded, only the schema errors are reported
+			expected: []string{
+				"clusterpolicy.yaml:5:1: schema: spec.toolkit: Required value",
+				`clusterpolicy.yaml:10:5: schema: spec.driver.enabled: Invalid value: "string": spec.driver.enabled in body must be of type boolean: "string"`,
+				"clusterpolicy.yaml:12:5: schema: spec.devicePlugin.unknownField: Forbidden: unknown field, it is dropped by the API server",
+			},
+		},
+		{
+			description: "semantic errors",
+			contents: `apiVersion: xdxct.com/v1
+kind: ClusterPolicy
+metadata:
+  name: cluster-policy
+spec:
+  operator:
+    defaultRuntime: containerd
+  daemonsets:
+    rollingUpdate:
+      maxUnavailable: one
+  driver:
+    enabled: true
+    env:
+      - name: A
+        value: a
+      - name: TOKEN
+        value: plain
+        valueFrom:
+          fieldRef:
+            fieldPath: spec.nodeName
+    livenessProbe:
+      successThreshold: 2
+  toolkit: {}
+  devicePlugin:
+    imagePullPolicy: always
+  gfd: {}
+  nodeStatusExporter: {}
+`,
+			expected: []string{
+				`clusterpolicy.yaml:10:7: semantic: spec.daemonsets.rollingUpdate.maxUnavailable: Invalid value: "one": must be an integer or a percentage (e.g. '1' or '25%')`,
+				`clusterpolicy.yaml:18:9: semantic: spec.driver.env[1].valueFrom: Invalid value: "": may not be specified when ` + "`value`" + ` is not empty`,
+				"clusterpolicy.yaml:22:7: semantic: spec.driver.livenessProbe.successThreshold: Invalid value: 2: must be 1",
+				`clusterpolicy.yaml:25:5: semantic: spec.devicePlugin.imagePullPolicy: Unsupported value: "always": supported values: "Always", "Never", "IfNotPresent"`,
+			},
+		},
+		{
+			description: "semantic error of a field with a schema error",
+			contents: `apiVersion: xdxct.com/v1
+kind: ClusterPolicy
+metadata:
+  name: cluster-policy
+spec:
+  operator:
+    defaultRuntime: podman
+  daemonsets: {}
+  driver:
+    enabled: true
+  toolkit: {}
+  devicePlugin:
+    unknownField: true
+  gfd: {}
+  nodeStatusExporter: {}
+`,
+			// the invalid runtime is reported by the schema checks only
+			expected: []string{
+				`clusterpolicy.yaml:7:5: schema: spec.operator.defaultRuntime: Unsupported value: "podman": supported values: "docker", "crio", "containerd"`,
+				"clusterpolicy.yaml:13:5: schema: spec.devicePlugin.unknownField: Forbidden: unknown field, it is dropped by the API server",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			o := &options{input: "clusterpolicy.yaml", offline: true}
+			findings, err := o.validate(context.Background(), []byte(tc.contents))
+			require.NoError(t, err)
+
+			var messages []string
+			for _, f := range findings {
+				messages = append(messages, f.String())
+			}
+			require.Equal(t, tc.expected, messages)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package clusterpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	sourceSchema   = "schema"
+	sourceSemantic = "semantic"
+	sourceImage    = "image"
+)
+
+// finding is a single problem found in the clusterpolicy
+type finding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+func (f finding) String() string {
+	position := f.File
+	if f.Line != 0 {
+		position = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	}
+	if f.Field == "" {
+		return fmt.Sprintf("%s: %s: %s", position, f.Source, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", position, f.Source, f.Field, f.Message)
+}
+
+// positions maps field paths of a yaml document to their line and column
+type positions struct {
+	root *yaml.Node
+}
+
+func newPositions(contents []byte) positions {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, doc); err != nil || len(doc.Content) == 0 {
+		return positions{}
+	}
+	return positions{root: doc.Content[0]}
+}
+
+// lookup returns the position of the field at path, such as spec.driver.env[0].name.
+// If the field is not set, the position of its closest parent is returned
+func (p positions) lookup(path string) (int, int) {
+	node := p.root
+	if node == nil {
+		return 0, 0
+	}
+	line, column := node.Line, node.Column
+
+	for _, element := range splitPath(path) {
+		var next, key *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == element {
+					key, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(element)
+			if err == nil && index >= 0 && index < len(node.Content) {
+				key, next = node.Content[index], node.Content[index]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+		line, column = key.Line, key.Column
+	}
+
+	return line, column
+}
+
+// splitPath splits a field path into its keys and indices,
+// e.g. spec.nodeSelector[xdxct.com/gpu].env[0] into spec, nodeSelector, xdxct.com/gpu, env, 0
+func splitPath(path string) []string {
+	var elements []string
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return append(elements, path[1:])
+			}
+			elements = append(elements, path[1:end])
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				return append(elements, path)
+			}
+			elements = append(elements, path[:end])
+			path = path[end:]
+		}
+	}
+	return elements
+}
+
+// newFindings converts errs into findings positioned in file
+func newFindings(file string, pos positions, source string, errs field.ErrorList) []finding {
+	var findings []finding
+	for _, err := range errs {
+		line, column := pos.lookup(err.Field)
+		findings = append(findings, finding{
+			File:    file,
+			Line:    line,
+			Column:  column,
+			Field:   err.Field,
+			Source:  source,
+			Message: err.ErrorBody(),
+		})
+	}
+	return findings
+}
+
+// sortFindings sorts findings by their position in the file
+func sortFindings(findings []finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+}
+
+func writeFindings(w io.Writer, output string, findings []finding) error {
+	if output == outputJSON {
+		if findings == nil {
+			findings = []finding{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(findings)
+	}
+
+	for _, f := range findings {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package clusterpolicy
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const positionsContents = `spec:
+  driver:
+    env:
+      - name: A
+        value: a
+      - name: B
+        valueFrom:
+          fieldRef:
+            fieldPath: spec.nodeName
+  nodeSelector:
+    xdxct.com/gpu.present: "true"
+`
+
+func TestPositionsLookup(t *testing.T) {
+	testCases := []struct {
+		description string
+		path        string
+		line        int
+		column      int
+	}{
+		{
+			description: "root",
+			path:        "",
+			line:        1,
+			column:      1,
+		},
+		{
+			description: "field",
+			path:        "spec.driver",
+			line:        2,
+			column:      3,
+		},
+		{
+			description: "list item",
+			path:        "spec.driver.env[1]",
+			line:        6,
+			column:      9,
+		},
+		{
+			description: "field of a list item",
+			path:        "spec.driver.env[1].valueFrom.fieldRef",
+			line:        8,
+			column:      11,
+		},
+		{
+			description: "list index out of range",
+			path:        "spec.driver.env[2].name",
+			line:        3,
+			column:      5,
+		},
+		{
+			description: "unset field",
+			path:        "spec.driver.env[0].valueFrom",
+			line:        4,
+			column:      9,
+		},
+		{
+			description: "map key with dots",
+			path:        "spec.nodeSelector[xdxct.com/gpu.present]",
+			line:        11,
+			column:      5,
+		},
+	}
+
+	pos := newPositions([]byte(positionsContents))
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			line, column := pos.lookup(tc.path)
+			require.Equal(t, tc.line, line)
+			require.Equal(t, tc.column, column)
+		})
+	}
+}
+
+func TestWriteFindingsJSON(t *testing.T) {
+	testCases := []struct {
+		description string
+		findings    []finding
+		expected    []map[string]interface{}
+	}{
+		{
+			description: "no findings",
+			expected:    []map[string]interface{}{},
+		},
+		{
+			description: "findings",
+			findings: []finding{
+				{
+					File:    "clusterpolicy.yaml",
+					Line:    7,
+					Column:  5,
+					Field:   "spec.operator.defaultRuntime",
+					Source:  sourceSchema,
+					Message: "Required value",
+				},
+				{
+					File:    "clusterpolicy.yaml",
+					Source:  sourceImage,
+					Message: "failed to get image",
+				},
+			},
+			expected: []map[string]interface{}{
+				{
+					"file":    "clusterpolicy.yaml",
+					"line":    float64(7),
+					"column":  float64(5),
+					"field":   "spec.operator.defaultRuntime",
+					"source":  "schema",
+					"message": "Required value",
+				},
+				{
+					"file":    "clusterpolicy.yaml",
+					"source":  "image",
+					"message": "failed to get image",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeFindings(&buf, outputJSON, tc.findings))
+
+			var output []map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+			require.Equal(t, tc.expected, output)
+		})
+	}
+}
@@ -19,109 +19,194 @@ package clusterpolicy
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	v1 "github.com/NVIDIA/gpu-operator/api/v1"
 	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/types/manifest"
 	"github.com/regclient/regclient/types/ref"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
-var client = regclient.New()
+// imageOptions configures how the images of a clusterpolicy are validated
+type imageOptions struct {
+	// registryDir holds an OCI image layout per repository, as
+	// <registryDir>/<registry>/<repository>. The registry is queried if unset
+	registryDir string
+	// osTags are appended to the driver image, one image per OS
+	osTags []string
+	// architectures every image must provide a manifest for
+	architectures []string
+}
 
-func validateImages(spec *v1.ClusterPolicySpec) error {
-	// Driver
-	path, err := v1.ImagePath(&spec.Driver)
-	if err != nil {
-		return fmt.Errorf("failed to construct the image path: %v", err)
-	}
-	// For driver, we must append the os-tag
-	path = path + "-ubuntu22.04"
+// imageValidator checks that images exist, validating every image only once
+type imageValidator struct {
+	client    *regclient.RegClient
+	opts      imageOptions
+	validated map[string]error
+}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+func newImageValidator(opts imageOptions) *imageValidator {
+	return &imageValidator{
+		client:    regclient.New(),
+		opts:      opts,
+		validated: map[string]error{},
 	}
+}
 
-	// Toolkit
-	path, err = v1.ImagePath(&spec.Toolkit)
-	if err != nil {
-		return fmt.Errorf("failed to construct the image path: %v", err)
-	}
+// validateImages checks the images of all enabled components and returns an error per missing image
+func validateImages(ctx context.Context, spec *v1.ClusterPolicySpec, opts imageOptions) field.ErrorList {
+	allErrs := field.ErrorList{}
+	v := newImageValidator(opts)
+	fldPath := field.NewPath("spec")
+
+	components := []struct {
+		path    *field.Path
+		spec    interface{}
+		enabled bool
+	}{
+		{fldPath.Child("driver", "manager"), &spec.Driver.Manager, spec.Driver.IsEnabled()},
+		{fldPath.Child("toolkit"), &spec.Toolkit, spec.Toolkit.IsEnabled()},
+		{fldPath.Child("devicePlugin"), &spec.DevicePlugin, spec.DevicePlugin.IsEnabled()},
+		{fldPath.Child("nodeStatusExporter"), &spec.NodeStatusExporter, spec.NodeStatusExporter.IsEnabled()},
+		{fldPath.Child("gfd"), &spec.GPUFeatureDiscovery, spec.GPUFeatureDiscovery.IsEnabled()},
+		{fldPath.Child("validator"), &spec.Validator, true},
+	}
+
+	// the tag of precompiled driver images depends on the kernel of the nodes
+	if spec.Driver.IsEnabled() && !spec.Driver.UsePrecompiledDrivers() {
+		path, err := v1.ImagePath(&spec.Driver, spec.Operator.ImageRegistryMirrors)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("driver"), "", err.Error()))
+		} else if len(opts.osTags) == 0 {
+			allErrs = append(allErrs, v.validate(ctx, fldPath.Child("driver", "version"), path)...)
+		} else {
+			for _, osTag := range opts.osTags {
+				// the operator appends the OS of the node to the driver image tag
+				allErrs = append(allErrs, v.validate(ctx, fldPath.Child("driver", "version"), path+"-"+osTag)...)
+			}
+		}
+	}
+
+	for _, c := range components {
+		if !c.enabled {
+			continue
+		}
+		path, err := v1.ImagePath(c.spec, spec.Operator.ImageRegistryMirrors)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(c.path, "", err.Error()))
+			continue
+		}
+		allErrs = append(allErrs, v.validate(ctx, c.path.Child("version"), path)...)
+	}
+
+	return allErrs
+}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+func (v *imageValidator) validate(ctx context.Context, fldPath *field.Path, image string) field.ErrorList {
+	err, ok := v.validated[image]
+	if !ok {
+		err = v.validateImage(ctx, image)
+		v.validated[image] = err
 	}
-
-	// Device Plugin
-	path, err = v1.ImagePath(&spec.DevicePlugin)
 	if err != nil {
-		return fmt.Errorf("failed to construct the image path: %v", err)
+		return field.ErrorList{field.Invalid(fldPath, image, err.Error())}
 	}
+	return nil
+}
 
-	err = validateImage(path)
+// validateImage checks that image exists and provides all required architectures
+func (v *imageValidator) validateImage(ctx context.Context, image string) error {
+	imageRef, err := v.imageRef(image)
 	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+		return fmt.Errorf("failed to construct an image reference: %v", err)
 	}
 
-	err = validateImage(path)
+	m, err := v.client.ManifestGet(ctx, imageRef)
 	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+		return fmt.Errorf("failed to get image manifest: %v", err)
 	}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+	if len(v.opts.architectures) == 0 {
+		return nil
 	}
-
-	// GPUFeatureDiscovery
-	path, err = v1.ImagePath(&spec.GPUFeatureDiscovery)
+	available, err := v.architectures(ctx, imageRef, m)
 	if err != nil {
-		return fmt.Errorf("failed to construct the image path: %v", err)
+		return err
 	}
-
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+	var missing []string
+	for _, arch := range v.opts.architectures {
+		if !available[arch] {
+			missing = append(missing, arch)
+		}
 	}
-
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+	if len(missing) != 0 {
+		return fmt.Errorf("no manifest for architecture(s) %s, available: %s", strings.Join(missing, ", "), strings.Join(sortedKeys(available), ", "))
 	}
+	return nil
+}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+// imageRef returns the reference of image in the registry, or in the
+// OCI layout of its repository if a registry directory is set
+func (v *imageValidator) imageRef(image string) (ref.Ref, error) {
+	imageRef, err := ref.New(image)
+	if err != nil || v.opts.registryDir == "" {
+		return imageRef, err
 	}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+	// ':' cannot be part of an OCI layout path, it separates the tag
+	registry := strings.ReplaceAll(imageRef.Registry, ":", "_")
+	layout := "ocidir://" + filepath.Join(v.opts.registryDir, registry, imageRef.Repository)
+	if imageRef.Tag != "" {
+		layout += ":" + imageRef.Tag
 	}
-
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
+	if imageRef.Digest != "" {
+		layout += "@" + imageRef.Digest
 	}
+	return ref.New(layout)
+}
 
-	err = validateImage(path)
-	if err != nil {
-		return fmt.Errorf("failed to validate image %s: %v", path, err)
-	}
+// architectures returns the linux architectures an image provides a manifest for
+func (v *imageValidator) architectures(ctx context.Context, imageRef ref.Ref, m manifest.Manifest) (map[string]bool, error) {
+	available := map[string]bool{}
 
-	return nil
-}
+	if m.IsList() {
+		platforms, err := manifest.GetPlatformList(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get platforms of image index: %v", err)
+		}
+		for _, p := range platforms {
+			if p.OS == "linux" {
+				available[p.Architecture] = true
+			}
+		}
+		return available, nil
+	}
 
-func validateImage(path string) error {
-	ref, err := ref.New(path)
+	// a single image manifest declares its architecture in its config
+	imager, ok := m.(manifest.Imager)
+	if !ok {
+		return nil, fmt.Errorf("unsupported manifest media type %s", m.GetDescriptor().MediaType)
+	}
+	desc, err := imager.GetConfig()
 	if err != nil {
-		return fmt.Errorf("failed to construct an image reference: %v", err)
+		return nil, fmt.Errorf("failed to get image config descriptor: %v", err)
 	}
-
-	_, err = client.ManifestGet(context.TODO(), ref)
+	config, err := v.client.BlobGetOCIConfig(ctx, imageRef, desc)
 	if err != nil {
-		return fmt.Errorf("failed to get image manifest: %v", err)
+		return nil, fmt.Errorf("failed to get image config: %v", err)
 	}
+	available[config.GetConfig().Architecture] = true
+	return available, nil
+}
 
-	return nil
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,259 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package clusterpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+)
+
+// registryDir holds an OCI image layout per repository:
+//   - registry.local:5000/nvidia/driver:535.104.05-ubuntu22.04, an amd64 image
+//   - nvcr.io/nvidia/k8s-device-plugin:v0.14.1, an amd64 and arm64 index
+//   - nvcr.io/nvidia/cloud-native/gpu-operator-validator:v23.6.1, an amd64 and arm64 index
+//   - nvcr.io/nvidia/cloud-native/k8s-driver-manager:v0.6.2, an amd64 and arm64 index
+const registryDir = "testdata/registry"
+
+// devicePluginDigest is the digest of the index of the device plugin image
+const devicePluginDigest = "sha256:729f59f650a1c1c6dd2a5f1fc0c792a6664f86f74523b5e6ee0ef2f149bcf089"
+
+func TestImageRef(t *testing.T) {
+	testCases := []struct {
+		description   string
+		image         string
+		registryDir   string
+		errorExpected bool
+		scheme        string
+		path          string
+		tag           string
+		digest        string
+	}{
+		{
+			description: "registry",
+			image:       "nvcr.io/nvidia/k8s-device-plugin:v0.14.1",
+			scheme:      "reg",
+			tag:         "v0.14.1",
+		},
+		{
+			description: "oci layout",
+			image:       "nvcr.io/nvidia/k8s-device-plugin:v0.14.1",
+			registryDir: registryDir,
+			scheme:      "ocidir",
+			path:        "testdata/registry/nvcr.io/nvidia/k8s-device-plugin",
+			tag:         "v0.14.1",
+		},
+		{
+			description: "registry with a port",
+			image:       "registry.local:5000/nvidia/driver:535.104.05-ubuntu22.04",
+			registryDir: registryDir,
+			scheme:      "ocidir",
+			path:        "testdata/registry/registry.local_5000/nvidia/driver",
+			tag:         "535.104.05-ubuntu22.04",
+		},
+		{
+			description: "digest",
+			image:       "nvcr.io/nvidia/k8s-device-plugin@" + devicePluginDigest,
+			registryDir: registryDir,
+			scheme:      "ocidir",
+			path:        "testdata/registry/nvcr.io/nvidia/k8s-device-plugin",
+			digest:      devicePluginDigest,
+		},
+		{
+			description: "docker hub",
+			image:       "busybox:1.36",
+			registryDir: registryDir,
+			scheme:      "ocidir",
+			path:        "testdata/registry/docker.io/library/busybox",
+			tag:         "1.36",
+		},
+		{
+			description:   "invalid image",
+			image:         "nvcr.io/nvidia/Driver::535",
+			registryDir:   registryDir,
+			errorExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			v := newImageValidator(imageOptions{registryDir: tc.registryDir})
+			imageRef, err := v.imageRef(tc.image)
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.scheme, imageRef.Scheme)
+			require.Equal(t, tc.path, imageRef.Path)
+			require.Equal(t, tc.tag, imageRef.Tag)
+			require.Equal(t, tc.digest, imageRef.Digest)
+		})
+	}
+}
+
+func TestArchitectures(t *testing.T) {
+	testCases := []struct {
+		description string
+		image       string
+		expected    map[string]bool
+	}{
+		{
+			description: "image index",
+			image:       "nvcr.io/nvidia/k8s-device-plugin:v0.14.1",
+			expected:    map[string]bool{"amd64": true, "arm64": true},
+		},
+		{
+			description: "image manifest",
+			image:       "registry.local:5000/nvidia/driver:535.104.05-ubuntu22.04",
+			expected:    map[string]bool{"amd64": true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			v := newImageValidator(imageOptions{registryDir: registryDir})
+			imageRef, err := v.imageRef(tc.image)
+			require.NoError(t, err)
+			m, err := v.client.ManifestGet(ctx, imageRef)
+			require.NoError(t, err)
+			available, err := v.architectures(ctx, imageRef, m)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, available)
+		})
+	}
+}
+
+func TestValidateImages(t *testing.T) {
+	enabled, disabled := true, false
+	newSpec := func() *v1.ClusterPolicySpec {
+		spec := &v1.ClusterPolicySpec{}
+		spec.Driver.Enabled = &enabled
+		spec.Driver.Repository = "registry.local:5000/nvidia"
+		spec.Driver.Image = "driver"
+		spec.Driver.Version = "535.104.05"
+		spec.Driver.Manager = v1.DriverManagerSpec{Repository: "nvcr.io/nvidia/cloud-native", Image: "k8s-driver-manager", Version: "v0.6.2"}
+		spec.Toolkit.Enabled = &disabled
+		spec.DevicePlugin.Enabled = &enabled
+		spec.DevicePlugin.Repository = "nvcr.io/nvidia"
+		spec.DevicePlugin.Image = "k8s-device-plugin"
+		spec.DevicePlugin.Version = "v0.14.1"
+		spec.NodeStatusExporter.Enabled = &disabled
+		spec.GPUFeatureDiscovery.Enabled = &disabled
+		spec.Validator.Repository = "nvcr.io/nvidia/cloud-native"
+		spec.Validator.Image = "gpu-operator-validator"
+		spec.Validator.Version = "v23.6.1"
+		return spec
+	}
+
+	testCases := []struct {
+		description string
+		update      func(spec *v1.ClusterPolicySpec)
+		opts        imageOptions
+		// expected maps the fields of the expected errors to a substring of their detail
+		expected map[string]string
+	}{
+		{
+			description: "images found",
+			opts:        imageOptions{osTags: []string{"ubuntu22.04"}},
+		},
+		{
+			description: "driver image missing for an os",
+			opts:        imageOptions{osTags: []string{"ubuntu22.04", "rhel8"}},
+			expected:    map[string]string{"spec.driver.version": "failed to get image manifest"},
+		},
+		{
+			description: "driver image without os tag",
+			expected:    map[string]string{"spec.driver.version": "failed to get image manifest"},
+		},
+		{
+			description: "precompiled driver skipped",
+			update: func(spec *v1.ClusterPolicySpec) {
+				spec.Driver.UsePrecompiled = &enabled
+			},
+			opts: imageOptions{osTags: []string{"rhel8"}},
+		},
+		{
+			description: "missing architecture",
+			opts:        imageOptions{osTags: []string{"ubuntu22.04"}, architectures: []string{"amd64", "arm64"}},
+			expected:    map[string]string{"spec.driver.version": "no manifest for architecture(s) arm64, available: amd64"},
+		},
+		{
+			description: "digest",
+			update: func(spec *v1.ClusterPolicySpec) {
+				spec.DevicePlugin.Version = devicePluginDigest
+			},
+			opts: imageOptions{osTags: []string{"ubuntu22.04"}, architectures: []string{"arm64"}},
+			expected: map[string]string{
+				"spec.driver.version": "no manifest for architecture(s) arm64",
+			},
+		},
+		{
+			description: "unknown digest",
+			update: func(spec *v1.ClusterPolicySpec) {
+				spec.DevicePlugin.Version = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+			},
+			opts:     imageOptions{osTags: []string{"ubuntu22.04"}},
+			expected: map[string]string{"spec.devicePlugin.version": "failed to get image manifest"},
+		},
+		{
+			description: "disabled component skipped",
+			update: func(spec *v1.ClusterPolicySpec) {
+				spec.DevicePlugin.Enabled = &disabled
+				spec.DevicePlugin.Version = "v0.0.0"
+			},
+			opts: imageOptions{osTags: []string{"ubuntu22.04"}},
+		},
+		{
+			description: "empty image path",
+			update: func(spec *v1.ClusterPolicySpec) {
+				spec.DevicePlugin.Repository = ""
+				spec.DevicePlugin.Image = ""
+				spec.DevicePlugin.Version = ""
+			},
+			opts:     imageOptions{osTags: []string{"ubuntu22.04"}},
+			expected: map[string]string{"spec.devicePlugin": "Empty image path"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			t.Setenv("DEVICE_PLUGIN_IMAGE", "")
+			spec := newSpec()
+			if tc.update != nil {
+				tc.update(spec)
+			}
+			tc.opts.registryDir = registryDir
+
+			errs := validateImages(context.Background(), spec, tc.opts)
+			actual := map[string]string{}
+			for _, err := range errs {
+				require.NotContains(t, actual, err.Field, "more than one error for %s", err.Field)
+				actual[err.Field] = err.Detail
+			}
+			require.Len(t, actual, len(tc.expected), "errors: %v", errs)
+			for field, detail := range tc.expected {
+				require.Contains(t, actual, field)
+				require.Contains(t, actual[field], detail)
+			}
+		})
+	}
+}
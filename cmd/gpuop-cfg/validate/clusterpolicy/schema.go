@@ -0,0 +1,136 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package clusterpolicy
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+
+	gpuoperator "github.com/NVIDIA/gpu-operator/deployments/gpu-operator"
+)
+
+// crdSchema validates objects against the OpenAPI schema of a CRD version,
+// the same way the API server does
+type crdSchema struct {
+	validator  *validate.SchemaValidator
+	structural *structuralschema.Structural
+}
+
+// loadCRDSchema reads the schema of the given version from a CRD manifest, or from the
+// CRD embedded in gpuop-cfg if file is empty
+func loadCRDSchema(file string, version string) (*crdSchema, error) {
+	contents := gpuoperator.ClusterPolicyCRD
+	if file != "" {
+		var err error
+		contents, err = os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := yaml.Unmarshal(contents, crd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CRD: %v", err)
+	}
+
+	var crdValidation *apiextensionsv1.CustomResourceValidation
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version {
+			crdValidation = v.Schema
+			break
+		}
+	}
+	if crdValidation == nil || crdValidation.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("CRD %s has no schema for version %q", crd.Name, version)
+	}
+
+	internal := &apiextensions.CustomResourceValidation{}
+	err = apiextensionsv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(crdValidation, internal, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CRD schema: %v", err)
+	}
+
+	validator, _, err := apiservervalidation.NewSchemaValidator(internal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema validator: %v", err)
+	}
+	structural, err := structuralschema.NewStructural(internal.OpenAPIV3Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create structural schema: %v", err)
+	}
+
+	return &crdSchema{validator: validator, structural: structural}, nil
+}
+
+// validate applies the defaults of the schema to obj and returns the fields
+// that are unknown to the schema or do not match it. Unknown fields are removed from obj
+func (s *crdSchema) validate(obj map[string]interface{}) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, path := range pruning.PruneWithOptions(obj, s.structural, true, structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true}) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath(path), "unknown field, it is dropped by the API server"))
+	}
+
+	applyDefaults(obj, s.structural)
+	allErrs = append(allErrs, apiservervalidation.ValidateCustomResource(nil, obj, s.validator)...)
+
+	return allErrs
+}
+
+// applyDefaults sets the defaults of the schema on the fields missing from x. As
+// done by the API server, null values of non-nullable fields are defaulted or dropped
+func applyDefaults(x interface{}, s *structuralschema.Structural) {
+	if s == nil {
+		return
+	}
+
+	switch x := x.(type) {
+	case map[string]interface{}:
+		for k, prop := range s.Properties {
+			v, found := x[k]
+			if found && v == nil && !prop.Nullable && prop.Default.Object == nil {
+				delete(x, k)
+				continue
+			}
+			if (!found || (v == nil && !prop.Nullable)) && prop.Default.Object != nil {
+				x[k] = runtime.DeepCopyJSONValue(prop.Default.Object)
+			}
+		}
+		for k := range x {
+			if prop, found := s.Properties[k]; found {
+				applyDefaults(x[k], &prop)
+			} else if s.AdditionalProperties != nil {
+				applyDefaults(x[k], s.AdditionalProperties.Structural)
+			}
+		}
+	case []interface{}:
+		for i := range x {
+			applyDefaults(x[i], s.Items)
+		}
+	}
+}
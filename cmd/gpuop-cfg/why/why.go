@@ -0,0 +1,262 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package why
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/controllers"
+)
+
+type command struct {
+	logger *logrus.Logger
+}
+
+type options struct {
+	node       string
+	component  string
+	namespace  string
+	kubeconfig string
+	states     string
+}
+
+// NewCommand constructs a why command with the specified logger
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'why' command
+	c := cli.Command{
+		Name:  "why",
+		Usage: "Explain why an operand is not running on a node",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "node",
+			Usage:       "Specify the node the operand should run on",
+			Destination: &opts.node,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "component",
+			Usage:       fmt.Sprintf("Specify the operand, one of %v", controllers.OperandComponents()),
+			Destination: &opts.component,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "namespace",
+			Usage:       "Specify the namespace the GPU Operator is installed in",
+			Value:       "gpu-operator",
+			Destination: &opts.namespace,
+		},
+		&cli.StringFlag{
+			Name:        "kubeconfig",
+			Usage:       "Specify the kubeconfig file of the cluster. The default loading rules of kubectl apply if unset",
+			Destination: &opts.kubeconfig,
+			EnvVars:     []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name: "states",
+			Usage: "Specify the comma-separated list of the states deployed by the operator. " +
+				"The --states argument of the operator Deployment in the namespace applies if unset",
+			Destination: &opts.states,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if _, err := controllers.OperandDeployLabel(opts.component); err != nil {
+		return err
+	}
+	if opts.namespace == "" {
+		return fmt.Errorf("the namespace must be specified")
+	}
+	if opts.states != "" {
+		if _, err := controllers.ParseOperandStates(opts.states); err != nil {
+			return fmt.Errorf("invalid --states: %v", err)
+		}
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	ctx := c.Context
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: opts.kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+
+	s, err := controllers.NewRenderScheme()
+	if err != nil {
+		return fmt.Errorf("failed to create scheme: %v", err)
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %v", err)
+	}
+
+	list := &v1.ClusterPolicyList{}
+	if err := kubeClient.List(ctx, list); err != nil {
+		return fmt.Errorf("failed to list clusterpolicies: %v", err)
+	}
+	if len(list.Items) == 0 {
+		fmt.Fprintln(c.App.Writer, "No ClusterPolicy found, the operator deploys no operand until one is created")
+		return nil
+	}
+	cp := &list.Items[0]
+
+	node := &corev1.Node{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: opts.node}, node); err != nil {
+		return fmt.Errorf("failed to get node %s: %v", opts.node, err)
+	}
+
+	daemonset, err := findDaemonSet(ctx, kubeClient, opts.namespace, opts.component)
+	if err != nil {
+		return err
+	}
+
+	states := opts.states
+	if states == "" {
+		states, err = operatorStates(ctx, kubeClient, opts.namespace)
+		if err != nil {
+			return err
+		}
+	}
+	// the states were validated if set by the flag, those of the operator by the operator
+	operandStates, err := controllers.ParseOperandStates(states)
+	if err != nil {
+		return fmt.Errorf("invalid states: %v", err)
+	}
+
+	checks, err := controllers.ExplainPlacement(cp, operandStates, opts.component, node, daemonset)
+	if err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if !check.Passed {
+			fmt.Fprintf(c.App.Writer, "%s is not scheduled on node %s: %s\n", opts.component, opts.node, check.Reason)
+			return nil
+		}
+		m.logger.Debugf("%s: %s", check.Check, check.Reason)
+	}
+
+	pod, err := findPod(ctx, kubeClient, daemonset, opts.node)
+	if err != nil {
+		return err
+	}
+	if pod == nil {
+		fmt.Fprintf(c.App.Writer, "Nothing blocks %s on node %s, but DaemonSet %s has not created its pod yet\n", opts.component, opts.node, daemonset.Name)
+		return nil
+	}
+	fmt.Fprintf(c.App.Writer, "Nothing blocks %s on node %s, its pod %s is %s\n", opts.component, opts.node, pod.Name, pod.Status.Phase)
+	return nil
+}
+
+// operatorStates returns the value of the --states argument of the operator Deployment
+// in namespace, or an empty string if the operator deploys its default states
+func operatorStates(ctx context.Context, c client.Client, namespace string) (string, error) {
+	list := &appsv1.DeploymentList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return "", fmt.Errorf("failed to list deployments: %v", err)
+	}
+	for _, deployment := range list.Items {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			args := append(append([]string{}, container.Command...), container.Args...)
+			for i, arg := range args {
+				// the flag package accepts both -states and --states, with or without "="
+				if !strings.HasPrefix(arg, "-") {
+					continue
+				}
+				name := strings.TrimLeft(arg, "-")
+				if strings.HasPrefix(name, "states=") {
+					return strings.TrimPrefix(name, "states="), nil
+				}
+				if name == "states" && i+1 < len(args) {
+					return args[i+1], nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// findDaemonSet returns the DaemonSet in namespace which selects the nodes by the deploy
+// label of component, or nil if there is none
+func findDaemonSet(ctx context.Context, c client.Client, namespace string, component string) (*appsv1.DaemonSet, error) {
+	deployLabel, err := controllers.OperandDeployLabel(component)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &appsv1.DaemonSetList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %v", err)
+	}
+	for i := range list.Items {
+		if _, ok := list.Items[i].Spec.Template.Spec.NodeSelector[deployLabel]; ok {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// findPod returns the pod of daemonset on the given node, or nil if there is none
+func findPod(ctx context.Context, c client.Client, daemonset *appsv1.DaemonSet, node string) (*corev1.Pod, error) {
+	list := &corev1.PodList{}
+	if err := c.List(ctx, list, client.InNamespace(daemonset.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		owner := metav1.GetControllerOf(pod)
+		if pod.Spec.NodeName == node && owner != nil && owner.UID == daemonset.UID {
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
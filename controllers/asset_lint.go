@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	secv1 "github.com/openshift/api/security/v1"
+)
+
+// assetTypes maps the kinds decoded by addResourcesControls to the type they are decoded into
+var assetTypes = map[string]func() runtime.Object{
+	"ServiceAccount":             func() runtime.Object { return &corev1.ServiceAccount{} },
+	"Role":                       func() runtime.Object { return &rbacv1.Role{} },
+	"RoleBinding":                func() runtime.Object { return &rbacv1.RoleBinding{} },
+	"ClusterRole":                func() runtime.Object { return &rbacv1.ClusterRole{} },
+	"ClusterRoleBinding":         func() runtime.Object { return &rbacv1.ClusterRoleBinding{} },
+	"ConfigMap":                  func() runtime.Object { return &corev1.ConfigMap{} },
+	"DaemonSet":                  func() runtime.Object { return &appsv1.DaemonSet{} },
+	"Deployment":                 func() runtime.Object { return &appsv1.Deployment{} },
+	"Service":                    func() runtime.Object { return &corev1.Service{} },
+	"ServiceMonitor":             func() runtime.Object { return &promv1.ServiceMonitor{} },
+	"SecurityContextConstraints": func() runtime.Object { return &secv1.SecurityContextConstraints{} },
+	"RuntimeClass":               func() runtime.Object { return &nodev1.RuntimeClass{} },
+	"PodSecurityPolicy":          func() runtime.Object { return &policyv1beta1.PodSecurityPolicy{} },
+	"PrometheusRule":             func() runtime.Object { return &promv1.PrometheusRule{} },
+}
+
+// assetLookup is a container or volume a transformation looks up by name in a DaemonSet
+type assetLookup struct {
+	// kind is one of initContainer, container, volume or volumeMount, the volume
+	// mounts of the first container
+	kind string
+	name string
+	// contains is true if the transformation matches every name containing name
+	contains bool
+	// optional is true if the transformation ignores the lookup when nothing matches,
+	// which only leaves the feature the lookup is needed for unavailable
+	optional bool
+	// hostPath is true if the transformation sets the path of the volume, which
+	// must then be a hostPath volume
+	hostPath bool
+	// usedBy is the transformation function which looks the name up
+	usedBy string
+}
+
+// daemonsetLookups lists the names each transformation in daemonsetTransformations
+// looks up in its DaemonSet. It must be kept in sync with the transformations
+var daemonsetLookups = map[string][]assetLookup{
+	"nvidia-driver-daemonset": {
+		{kind: "initContainer", name: "k8s-driver-manager", usedBy: "transformDriverManagerInitContainer"},
+		{kind: "initContainer", name: "mofed-validation", contains: true, optional: true, usedBy: "transformValidationInitContainer"},
+		{kind: "container", name: "nvidia-driver-ctr", usedBy: "transformDriverContainer"},
+		{kind: "container", name: "nvidia-peermem", contains: true, optional: true, usedBy: "transformPeerMemoryContainer"},
+		{kind: "container", name: "openshift-driver-toolkit-ctr", optional: true, usedBy: "transformOpenShiftDriverToolkitContainer"},
+		{kind: "volume", name: "mlnx-ofed-usr-src", optional: true, hostPath: true, usedBy: "transformDriverContainer"},
+	},
+	"xdxct-container-toolkit-daemonset": {
+		{kind: "volume", name: "toolkit-install-dir", hostPath: true, usedBy: "TransformToolkit"},
+		{kind: "volumeMount", name: "toolkit-install-dir", usedBy: "TransformToolkit"},
+	},
+	"xdxct-device-plugin-daemonset": {
+		{kind: "initContainer", name: "config-manager-init", optional: true, usedBy: "transformConfigManagerInitContainer"},
+		{kind: "container", name: "xdxct-device-plugin", usedBy: "handleDevicePluginConfig"},
+		{kind: "container", name: "config-manager", optional: true, usedBy: "transformConfigManagerSidecarContainer"},
+	},
+	"gpu-feature-discovery": {
+		{kind: "initContainer", name: "config-manager-init", optional: true, usedBy: "transformConfigManagerInitContainer"},
+		{kind: "container", name: "gpu-feature-discovery", usedBy: "handleDevicePluginConfig"},
+		{kind: "container", name: "config-manager", optional: true, usedBy: "transformConfigManagerSidecarContainer"},
+	},
+	"nvidia-operator-validator": {
+		{kind: "initContainer", name: "driver-validation", contains: true, optional: true, usedBy: "TransformValidatorComponent"},
+		{kind: "initContainer", name: "nvidia-fs-validation", contains: true, optional: true, usedBy: "TransformValidatorComponent"},
+		{kind: "initContainer", name: "toolkit-validation", contains: true, optional: true, usedBy: "TransformValidatorComponent"},
+		{kind: "initContainer", name: "cuda-validation", contains: true, optional: true, usedBy: "TransformValidatorComponent"},
+		{kind: "initContainer", name: "plugin-validation", contains: true, optional: true, usedBy: "TransformValidatorComponent"},
+	},
+}
+
+// AssetProblem is a problem found in an asset file by LintAssets
+type AssetProblem struct {
+	File string
+	// Warning is true if the operator deploys the asset, with a feature unavailable
+	Warning bool
+	Message string
+}
+
+// LintAssets checks the assets of every state directory in assetsDir the way the
+// operator loads them: every manifest must decode into the type of its kind, or
+// into an unstructured object for the kinds without a typed control, and
+// every DaemonSet must have a transformation, and contain the containers and
+// volumes the transformation looks up by name
+func LintAssets(assetsDir string) ([]AssetProblem, error) {
+	entries, err := os.ReadDir(assetsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assets directory: %v", err)
+	}
+
+	var problems []AssetProblem
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		var files []string
+		err := filepath.Walk(filepath.Join(assetsDir, entry.Name()), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk state %s: %v", entry.Name(), err)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			m, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read asset: %v", err)
+			}
+			for _, problem := range lintAsset(m) {
+				problem.File = file
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems, nil
+}
+
+// lintAsset checks a single manifest, without setting the file of the problems found
+func lintAsset(m assetsFromFile) []AssetProblem {
+	kind := assetKind(m)
+	if kind == "" {
+		return []AssetProblem{{Message: "manifest has no kind, it is ignored"}}
+	}
+	newObject, ok := assetTypes[kind]
+	if !ok {
+		// kinds without a typed control are applied as is
+		if _, err := decodeUnstructured(m); err != nil {
+			return []AssetProblem{{Message: fmt.Sprintf("failed to decode %s, the operator fails to load its state: %v", kind, err)}}
+		}
+		return nil
+	}
+
+	s := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme,
+		scheme.Scheme, json.SerializerOptions{Yaml: true, Pretty: false, Strict: false})
+	obj := newObject()
+	_, gvk, err := s.Decode(m, nil, obj)
+	if err != nil {
+		return []AssetProblem{{Message: fmt.Sprintf("failed to decode %s, the operator fails to load its state: %v", kind, err)}}
+	}
+	if gvk != nil && gvk.Kind != kind {
+		return []AssetProblem{{Message: fmt.Sprintf("the first kind field of the manifest is %s but it is a %s, move its kind to the top", kind, gvk.Kind)}}
+	}
+
+	var problems []AssetProblem
+	strict := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme,
+		scheme.Scheme, json.SerializerOptions{Yaml: true, Pretty: false, Strict: true})
+	if _, _, err := strict.Decode(m, nil, newObject()); err != nil {
+		problems = append(problems, AssetProblem{Warning: true, Message: fmt.Sprintf("%s has fields the operator ignores: %v", kind, err)})
+	}
+
+	if ds, ok := obj.(*appsv1.DaemonSet); ok {
+		problems = append(problems, lintDaemonSet(ds)...)
+	}
+	return problems
+}
+
+// lintDaemonSet checks ds has a transformation and the names the transformation looks up
+func lintDaemonSet(ds *appsv1.DaemonSet) []AssetProblem {
+	if _, ok := daemonsetTransformations[ds.Name]; !ok {
+		return []AssetProblem{{Message: fmt.Sprintf("DaemonSet %s has no transformation, its images and settings are not set from the ClusterPolicy; known DaemonSets are [%s]",
+			ds.Name, strings.Join(sortedDaemonsetTransformations(), ", "))}}
+	}
+
+	podSpec := &ds.Spec.Template.Spec
+	// every transformation sets the image of the first container
+	if len(podSpec.Containers) == 0 {
+		return []AssetProblem{{Message: fmt.Sprintf("DaemonSet %s has no container, its transformation panics", ds.Name)}}
+	}
+
+	var problems []AssetProblem
+	for _, lookup := range daemonsetLookups[ds.Name] {
+		var names []string
+		switch lookup.kind {
+		case "initContainer":
+			for _, c := range podSpec.InitContainers {
+				names = append(names, c.Name)
+			}
+		case "container":
+			for _, c := range podSpec.Containers {
+				names = append(names, c.Name)
+			}
+		case "volume":
+			for _, v := range podSpec.Volumes {
+				names = append(names, v.Name)
+				if lookup.hostPath && v.Name == lookup.name && v.HostPath == nil {
+					problems = append(problems, AssetProblem{Message: fmt.Sprintf("volume %s of DaemonSet %s is not a hostPath volume, %s panics setting its path",
+						v.Name, ds.Name, lookup.usedBy)})
+				}
+			}
+		case "volumeMount":
+			for _, m := range podSpec.Containers[0].VolumeMounts {
+				names = append(names, m.Name)
+			}
+		}
+
+		found := false
+		for _, name := range names {
+			if name == lookup.name || (lookup.contains && strings.Contains(name, lookup.name)) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		match := lookup.name
+		if lookup.contains {
+			match = "containing " + lookup.name
+		}
+		message := fmt.Sprintf("DaemonSet %s has no %s named %s, which %s looks up; found [%s]",
+			ds.Name, lookup.kind, match, lookup.usedBy, strings.Join(names, ", "))
+		if lookup.optional {
+			message += ", the feature it is needed for is unavailable"
+		}
+		problems = append(problems, AssetProblem{Warning: lookup.optional, Message: message})
+	}
+	return problems
+}
+
+func sortedDaemonsetTransformations() []string {
+	names := make([]string, 0, len(daemonsetTransformations))
+	for name := range daemonsetTransformations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const lintDevicePluginDaemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: xdxct-device-plugin-daemonset
+spec:
+  selector:
+    matchLabels:
+      app: xdxct-device-plugin-daemonset
+  template:
+    spec:
+      initContainers:
+      - name: config-manager-init
+        image: config-manager
+      containers:
+      - name: %s
+        image: device-plugin
+      - name: config-manager
+        image: config-manager
+`
+
+func TestLintAssets(t *testing.T) {
+	testCases := []struct {
+		description string
+		manifest    string
+		// problem is part of the message of the problem expected, empty if none is
+		problem string
+		warning bool
+	}{
+		{
+			description: "valid daemonset",
+			manifest:    fmt.Sprintf(lintDevicePluginDaemonSet, "xdxct-device-plugin"),
+		},
+		{
+			description: "renamed container",
+			manifest:    fmt.Sprintf(lintDevicePluginDaemonSet, "nvidia-device-plugin"),
+			problem:     "DaemonSet xdxct-device-plugin-daemonset has no container named xdxct-device-plugin, which handleDevicePluginConfig looks up; found [nvidia-device-plugin, config-manager]",
+		},
+		{
+			description: "missing sidecar",
+			manifest: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: xdxct-device-plugin-daemonset
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: config-manager-init
+      containers:
+      - name: xdxct-device-plugin
+`,
+			problem: "DaemonSet xdxct-device-plugin-daemonset has no container named config-manager, which transformConfigManagerSidecarContainer looks up; found [xdxct-device-plugin], the feature it is needed for is unavailable",
+			warning: true,
+		},
+		{
+			description: "no transformation",
+			manifest: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: xdxct-dcgm-exporter
+spec:
+  template:
+    spec:
+      containers:
+      - name: dcgm-exporter
+`,
+			problem: "DaemonSet xdxct-dcgm-exporter has no transformation, its images and settings are not set from the ClusterPolicy; known DaemonSets are [gpu-feature-discovery, nvidia-driver-daemonset, nvidia-node-status-exporter, nvidia-operator-validator, xdxct-container-toolkit-daemonset, xdxct-device-plugin-daemonset]",
+		},
+		{
+			description: "toolkit volume not a hostPath",
+			manifest: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: xdxct-container-toolkit-daemonset
+spec:
+  template:
+    spec:
+      containers:
+      - name: xdxct-container-toolkit-ctr
+        volumeMounts:
+        - name: toolkit-install-dir
+          mountPath: /usr/local/xdxct
+      volumes:
+      - name: toolkit-install-dir
+        emptyDir: {}
+`,
+			problem: "volume toolkit-install-dir of DaemonSet xdxct-container-toolkit-daemonset is not a hostPath volume, TransformToolkit panics setting its path",
+		},
+		{
+			description: "kind without typed control",
+			manifest: `apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: pdb
+`,
+		},
+		{
+			description: "kind without typed control missing its name",
+			manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: job-
+`,
+			problem: "failed to decode Job, the operator fails to load its state: Job has no name",
+		},
+		{
+			description: "decode error",
+			manifest: `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: [service-account]
+`,
+			problem: "failed to decode ServiceAccount, the operator fails to load its state",
+		},
+		{
+			description: "unknown field",
+			manifest: `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: service-account
+  nmespace: gpu-operator
+`,
+			problem: `ServiceAccount has fields the operator ignores: strict decoding error: unknown field "metadata.nmespace"`,
+			warning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assetsDir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(assetsDir, "state-test"), 0755))
+			file := filepath.Join(assetsDir, "state-test", "0100_manifest.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tc.manifest), 0600))
+
+			problems, err := LintAssets(assetsDir)
+			require.NoError(t, err)
+
+			if tc.problem == "" {
+				require.Empty(t, problems)
+				return
+			}
+			require.Len(t, problems, 1)
+			require.Equal(t, file, problems[0].File)
+			require.Contains(t, problems[0].Message, tc.problem)
+			require.Equal(t, tc.warning, problems[0].Warning)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/NVIDIA/gpu-operator/assets"
+)
+
+// AssetsFS returns the assets of the states embedded in the operator. The files of
+// overlayDir, if set, replace the embedded files with the same path or are added to
+// them. Like the embedded assets, overlayDir holds a directory per state, e.g.
+// state-device-plugin/0400_daemonset.yaml
+func AssetsFS(overlayDir string) (fs.FS, error) {
+	if overlayDir == "" {
+		return assets.FS, nil
+	}
+	info, err := os.Stat(overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assets overlay: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("assets overlay %s is not a directory", overlayDir)
+	}
+	return overlayFS{base: assets.FS, overlay: os.DirFS(overlayDir)}, nil
+}
+
+// overlayFS is a file system whose files are those of overlay, falling back to those of base
+type overlayFS struct {
+	base    fs.FS
+	overlay fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.overlay.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	return o.base.Open(name)
+}
+
+// ReadDir returns the entries of the directory in both file systems, sorted by name
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, overlayErr := fs.ReadDir(o.overlay, name)
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+	if overlayErr != nil && baseErr != nil {
+		return nil, baseErr
+	}
+
+	overlaid := map[string]bool{}
+	for _, entry := range entries {
+		overlaid[entry.Name()] = true
+	}
+	for _, entry := range baseEntries {
+		if !overlaid[entry.Name()] {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
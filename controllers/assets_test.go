@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetsFS(t *testing.T) {
+	// the embedded assets are those of the repository
+	embedded, err := AssetsFS("")
+	require.NoError(t, err)
+	for _, state := range operandStates {
+		expected, err := getAssetsFrom(os.DirFS(filepath.Join(cfg.root, "assets")), state)
+		require.NoError(t, err)
+		manifests, err := getAssetsFrom(embedded, state)
+		require.NoError(t, err)
+		require.Equal(t, expected, manifests, state)
+	}
+
+	overlayDir := t.TempDir()
+	stateDir := filepath.Join(overlayDir, "state-device-plugin")
+	require.NoError(t, os.Mkdir(stateDir, 0o755))
+	serviceAccount := []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: site-device-plugin\n")
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "0100_service_account.yaml"), serviceAccount, 0o644))
+	configMap := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: site-config\n")
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "0150_configmap.yaml"), configMap, 0o644))
+
+	overlay, err := AssetsFS(overlayDir)
+	require.NoError(t, err)
+
+	entries, err := fs.ReadDir(overlay, "state-device-plugin")
+	require.NoError(t, err)
+	embeddedEntries, err := fs.ReadDir(embedded, "state-device-plugin")
+	require.NoError(t, err)
+	// the ServiceAccount is replaced and the ConfigMap added
+	require.Len(t, entries, len(embeddedEntries)+1)
+
+	r := &ClusterPolicyReconciler{Log: logr.Discard()}
+	states, err := loadStateAssets(r, overlay, []string{"state-device-plugin"})
+	require.NoError(t, err)
+	res := states.resources[0]
+	require.Equal(t, "site-device-plugin", res.ServiceAccount.Name)
+	require.Len(t, res.ConfigMaps, 1)
+	require.Equal(t, "site-config", res.ConfigMaps[0].Name)
+	// the other manifests are the embedded ones
+	require.Equal(t, "xdxct-device-plugin-daemonset", res.DaemonSet.Name)
+
+	// states without overlay are read from the embedded assets
+	toolkit, err := getAssetsFrom(overlay, "state-container-toolkit")
+	require.NoError(t, err)
+	expected, err := getAssetsFrom(embedded, "state-container-toolkit")
+	require.NoError(t, err)
+	require.Equal(t, expected, toolkit)
+
+	_, err = AssetsFS(filepath.Join(overlayDir, "does-not-exist"))
+	require.Error(t, err)
+}
@@ -18,12 +18,20 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/mod/semver"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -33,6 +41,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -40,22 +49,53 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	operandassets "github.com/NVIDIA/gpu-operator/assets"
 )
 
 const (
 	minDelayCR = 100 * time.Millisecond
 	maxDelayCR = 3 * time.Second
+
+	// clusterPolicyFinalizer is set on the active ClusterPolicy so that node labels
+	// and namespace changes made by the operator are reverted on deletion
+	clusterPolicyFinalizer = "xdxct.com/clusterpolicy-cleanup"
+
+	reasonReconcileFailed = "ReconcileFailed"
 )
 
 // blank assignment to verify that ReconcileClusterPolicy implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ClusterPolicyReconciler{}
-var clusterPolicyCtrl ClusterPolicyController
 
 // ClusterPolicyReconciler reconciles a ClusterPolicy object
 type ClusterPolicyReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	// APIReader reads the objects the operator does not watch, such as image pull
+	// secrets, from the API server instead of the cache, Client is used if nil
+	APIReader client.Reader
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	// OperatorNamespace is the namespace the operator and its operands are deployed to
+	OperatorNamespace string
+	// Metrics are updated by every reconciliation, nothing is reported if nil
+	Metrics *OperatorMetrics
+	// Assets hold the manifests of the states, the assets embedded in the operator if nil
+	Assets fs.FS
+	// States are the states deployed by the operator, DefaultOperandStates() if empty
+	States []string
+
+	// states are loaded from the assets on the first reconciliation and shared read-only
+	statesOnce sync.Once
+	states     *stateAssets
+	statesErr  error
+
+	// mu guards the state kept across reconciliations
+	mu sync.Mutex
+	// k8sVersion is the version of the API server, detected on the first reconciliation
+	k8sVersion string
+	// imageDigests are the image digests pinned by the last reconciliation
+	imageDigests imageDigests
+	// active is the name of the ClusterPolicy reconciled last
+	active string
 }
 
 // +kubebuilder:rbac:groups=xdxct.com,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -66,7 +106,9 @@ type ClusterPolicyReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrule,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -80,14 +122,25 @@ type ClusterPolicyReconciler struct {
 func (r *ClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("Reconciling ClusterPolicy", req.NamespacedName)
 
+	metrics := r.Metrics
+	if metrics == nil {
+		// unregistered metrics, their updates are discarded
+		metrics = newOperatorMetrics()
+	}
+
 	// Fetch the ClusterPolicy instance
 	instance := &gpuv1.ClusterPolicy{}
 	err := r.Client.Get(ctx, req.NamespacedName, instance)
 	if err != nil {
-		clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
+		metrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			if r.resetActive(req.Name) {
+				// the active ClusterPolicy was deleted, the next one (if any)
+				// is enqueued by the ClusterPolicy delete watch and takes over
+				r.Log.Info("Active ClusterPolicy deleted", "name", req.Name)
+			}
 			// Return and don't requeue
 			return reconcile.Result{}, nil
 		}
@@ -95,71 +148,108 @@ func (r *ClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return reconcile.Result{}, err
 	}
 
-	// TODO: Handle deletion of the main ClusterPolicy and cycle to the next one.
-	// We already have a main Clusterpolicy
-	if clusterPolicyCtrl.singleton != nil && clusterPolicyCtrl.singleton.ObjectMeta.Name != instance.ObjectMeta.Name {
-		instance.SetStatus(gpuv1.Ignored, clusterPolicyCtrl.operatorNamespace)
-		// do not change `clusterPolicyCtrl.operatorMetrics.reconciliationStatus` here,
-		// spurious reconciliation
-		return ctrl.Result{}, err
+	active, err := getActiveClusterPolicy(ctx, r)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
-	err = clusterPolicyCtrl.init(ctx, r, instance)
-	if err != nil {
-		r.Log.Error(err, "Failed to initialize ClusterPolicy controller")
+	if instance.ObjectMeta.DeletionTimestamp != nil {
+		return r.finalizeClusterPolicy(ctx, instance, active)
+	}
 
-		if clusterPolicyCtrl.operatorMetrics != nil {
-			clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
+	if active != nil && active.ObjectMeta.Name == instance.ObjectMeta.Name &&
+		!controllerutil.ContainsFinalizer(instance, clusterPolicyFinalizer) {
+		controllerutil.AddFinalizer(instance, clusterPolicyFinalizer)
+		err = r.Client.Update(ctx, instance)
+		if err != nil {
+			r.Log.Error(err, "Failed to add finalizer to ClusterPolicy")
+			return reconcile.Result{}, err
 		}
+	}
+
+	if active != nil && active.ObjectMeta.Name != instance.ObjectMeta.Name {
+		// Only the oldest ClusterPolicy is reconciled, all others are ignored.
+		// do not change `reconciliationStatus` metric here, spurious reconciliation
+		r.Log.Info("ClusterPolicy is ignored, another instance is active", "name", instance.ObjectMeta.Name, "active", active.ObjectMeta.Name)
+		return ctrl.Result{}, updateIgnoredCRState(ctx, r, instance, active.ObjectMeta.Name)
+	}
+	if previous := r.setActive(instance.ObjectMeta.Name); previous != "" && previous != instance.ObjectMeta.Name {
+		r.Log.Info("Switching active ClusterPolicy", "previous", previous, "active", instance.ObjectMeta.Name)
+	}
+
+	// the controller state is rebuilt by every reconciliation
+	n := &ClusterPolicyController{}
+	err = n.init(ctx, r, instance, metrics)
+	if err != nil {
+		r.Log.Error(err, "Failed to initialize ClusterPolicy controller")
+		metrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
 		return ctrl.Result{}, err
 	}
+	// keep the image digests pinned by this reconciliation for the next ones
+	defer r.cacheImageDigests(n.imageDigests)
+
+	err = n.validate()
+	if err != nil {
+		r.Log.Error(err, "Invalid ClusterPolicy spec")
+		metrics.reconciliationStatus.Set(reconciliationStatusNotReady)
+		metrics.reconciliationFailed.Inc()
+		updateCRState(ctx, n, req.NamespacedName, gpuv1.NotReady, nil, err)
+		// do not requeue, a spec update will trigger a new reconciliation
+		return ctrl.Result{}, nil
+	}
 
-	if !clusterPolicyCtrl.hasNFDLabels {
+	if !n.hasNFDLabels {
 		r.Log.Info("WARNING: NFD labels missing in the cluster, GPU nodes cannot be discovered.")
-		clusterPolicyCtrl.operatorMetrics.reconciliationHasNFDLabels.Set(0)
+		metrics.reconciliationHasNFDLabels.Set(0)
 	} else {
-		clusterPolicyCtrl.operatorMetrics.reconciliationHasNFDLabels.Set(1)
+		metrics.reconciliationHasNFDLabels.Set(1)
 	}
-	if !clusterPolicyCtrl.hasGPUNodes {
+	if !n.hasGPUNodes {
 		r.Log.Info("No GPU node can be found in the cluster.")
 	}
 
-	clusterPolicyCtrl.operatorMetrics.reconciliationTotal.Inc()
+	metrics.reconciliationTotal.Inc()
 	overallStatus := gpuv1.Ready
 	statesNotReady := []string{}
-	for {
-		status, statusError := clusterPolicyCtrl.step()
-		if statusError != nil {
-			clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusNotReady)
-			clusterPolicyCtrl.operatorMetrics.reconciliationFailed.Inc()
-			updateCRState(ctx, r, req.NamespacedName, gpuv1.NotReady)
-			return ctrl.Result{RequeueAfter: time.Second * 5}, statusError
+	stateStatuses := []gpuv1.StateStatus{}
+	var statusError error
+	for _, result := range n.reconcileStates() {
+		stateStatuses = append(stateStatuses, result.stateStatus())
+		if result.err != nil {
+			r.Log.Error(result.err, "ClusterPolicy step failed", "state", result.name)
+			if statusError == nil {
+				statusError = result.err
+			}
+			continue
 		}
 
-		if status == gpuv1.NotReady {
+		if result.status == gpuv1.NotReady || result.status == gpuv1.Skipped {
 			overallStatus = gpuv1.NotReady
-			statesNotReady = append(statesNotReady, clusterPolicyCtrl.stateNames[clusterPolicyCtrl.idx-1])
+			statesNotReady = append(statesNotReady, result.name)
 		}
 		r.Log.Info("ClusterPolicy step completed",
-			"state:", clusterPolicyCtrl.stateNames[clusterPolicyCtrl.idx-1],
-			"status", status)
+			"state:", result.name,
+			"status", result.status)
+	}
 
-		if clusterPolicyCtrl.last() {
-			break
-		}
+	if statusError != nil {
+		metrics.reconciliationStatus.Set(reconciliationStatusNotReady)
+		metrics.reconciliationFailed.Inc()
+		updateCRState(ctx, n, req.NamespacedName, gpuv1.NotReady, stateStatuses, statusError)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, statusError
 	}
 
 	// if any state is not ready, requeue for reconfile after 5 seconds
 	if overallStatus != gpuv1.Ready {
-		clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusNotReady)
-		clusterPolicyCtrl.operatorMetrics.reconciliationFailed.Inc()
+		metrics.reconciliationStatus.Set(reconciliationStatusNotReady)
+		metrics.reconciliationFailed.Inc()
 
 		r.Log.Info("ClusterPolicy isn't ready", "states not ready", statesNotReady)
-		updateCRState(ctx, r, req.NamespacedName, gpuv1.NotReady)
+		updateCRState(ctx, n, req.NamespacedName, gpuv1.NotReady, stateStatuses, nil)
 		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 	}
 
-	if !clusterPolicyCtrl.hasNFDLabels {
+	if !n.hasNFDLabels {
 		// no NFD-labelled node in the cluster (required dependency),
 		// watch periodically for the labels to appear
 		var requeueAfter = time.Second * 45
@@ -167,19 +257,19 @@ func (r *ClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			"requeueAfter", requeueAfter)
 
 		// Update CR state as ready as all states are complete
-		updateCRState(ctx, r, req.NamespacedName, gpuv1.Ready)
-		clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusSuccess)
+		updateCRState(ctx, n, req.NamespacedName, gpuv1.Ready, stateStatuses, nil)
+		metrics.reconciliationStatus.Set(reconciliationStatusSuccess)
 
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// Update CR state as ready as all states are complete
-	updateCRState(ctx, r, req.NamespacedName, gpuv1.Ready)
-	clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusSuccess)
-	clusterPolicyCtrl.operatorMetrics.reconciliationLastSuccess.Set(float64(time.Now().Unix()))
+	updateCRState(ctx, n, req.NamespacedName, gpuv1.Ready, stateStatuses, nil)
+	metrics.reconciliationStatus.Set(reconciliationStatusSuccess)
+	metrics.reconciliationLastSuccess.Set(float64(time.Now().Unix()))
 
-	if !clusterPolicyCtrl.hasGPUNodes {
-		r.Log.Info("No GPU node found, watching for new nodes to join the cluster.", "hasNFDLabels", clusterPolicyCtrl.hasNFDLabels)
+	if !n.hasGPUNodes {
+		r.Log.Info("No GPU node found, watching for new nodes to join the cluster.", "hasNFDLabels", n.hasNFDLabels)
 	} else {
 		r.Log.Info("ClusterPolicy is ready.")
 	}
@@ -187,7 +277,73 @@ func (r *ClusterPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	return ctrl.Result{}, nil
 }
 
-func updateCRState(ctx context.Context, r *ClusterPolicyReconciler, namespacedName types.NamespacedName, state gpuv1.State) error {
+// setActive records the name of the ClusterPolicy being reconciled and returns the previous one
+func (r *ClusterPolicyReconciler) setActive(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.active
+	r.active = name
+	return previous
+}
+
+// resetActive forgets the active ClusterPolicy if it has the given name, and
+// returns whether it had
+func (r *ClusterPolicyReconciler) resetActive(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active != name {
+		return false
+	}
+	r.active = ""
+	return true
+}
+
+// kubernetesVersion returns the version of the API server, detected once
+func (r *ClusterPolicyReconciler) kubernetesVersion() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.k8sVersion != "" {
+		return r.k8sVersion, nil
+	}
+
+	k8sVersion, err := KubernetesVersion()
+	if err != nil {
+		return "", err
+	}
+	if !semver.IsValid(k8sVersion) {
+		return "", fmt.Errorf("k8s version detected '%s' is not a valid semantic version", k8sVersion)
+	}
+	r.Log.Info("Kubernetes version detected", "version", k8sVersion)
+	r.k8sVersion = k8sVersion
+	return k8sVersion, nil
+}
+
+// apiReader returns the reader of the objects the operator does not watch
+func (r *ClusterPolicyReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// stateAssets returns the states loaded from the assets, which must not be modified
+func (r *ClusterPolicyReconciler) stateAssets() (*stateAssets, error) {
+	r.statesOnce.Do(func() {
+		assets := r.Assets
+		if assets == nil {
+			assets = operandassets.FS
+		}
+		states := r.States
+		if len(states) == 0 {
+			states = defaultOperandStates
+		}
+		r.states, r.statesErr = loadStateAssets(r, assets, states)
+	})
+	return r.states, r.statesErr
+}
+
+func updateCRState(ctx context.Context, n *ClusterPolicyController, namespacedName types.NamespacedName, state gpuv1.State, states []gpuv1.StateStatus, reconcileErr error) error {
+	r := n.rec
 	// Fetch latest instance and update state to avoid version mismatch
 	instance := &gpuv1.ClusterPolicy{}
 	err := r.Client.Get(ctx, namespacedName, instance)
@@ -195,12 +351,30 @@ func updateCRState(ctx context.Context, r *ClusterPolicyReconciler, namespacedNa
 		r.Log.Error(err, "Failed to get ClusterPolicy instance for status update")
 		return err
 	}
-	if instance.Status.State == state {
-		// state is unchanged
+	oldStatus := instance.Status.DeepCopy()
+
+	instance.SetStatus(state, n.operatorNamespace)
+	for _, ss := range states {
+		ss.ObservedGeneration = instance.Generation
+		instance.SetStateStatus(ss)
+	}
+	setClusterPolicyConditions(instance, states, reconcileErr)
+
+	if instance.Spec.Operator.IsPinImageDigestsEnabled() {
+		if digests := n.getImageDigestsStatus(instance.Generation); len(digests) > 0 {
+			instance.Status.ImageDigests = digests
+			instance.Status.ImageDigestsGeneration = instance.Generation
+		}
+	} else {
+		instance.Status.ImageDigests = nil
+		instance.Status.ImageDigestsGeneration = 0
+	}
+
+	if equality.Semantic.DeepEqual(oldStatus, &instance.Status) {
+		// status is unchanged
 		return nil
 	}
 	// Update the CR state
-	instance.SetStatus(state, clusterPolicyCtrl.operatorNamespace)
 	err = r.Client.Status().Update(ctx, instance)
 	if err != nil {
 		r.Log.Error(err, "Failed to update ClusterPolicy status")
@@ -209,6 +383,189 @@ func updateCRState(ctx context.Context, r *ClusterPolicyReconciler, namespacedNa
 	return nil
 }
 
+// finalizeClusterPolicy reverts the node labels and annotations and the namespace
+// labels set by the operator, then releases the finalizer of a deleted ClusterPolicy.
+// The cleanup is skipped when another ClusterPolicy takes over.
+func (r *ClusterPolicyReconciler) finalizeClusterPolicy(ctx context.Context, instance *gpuv1.ClusterPolicy, next *gpuv1.ClusterPolicy) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, clusterPolicyFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if next == nil {
+		r.Log.Info("ClusterPolicy deleted, removing GPU Operator labels from the cluster", "name", instance.ObjectMeta.Name)
+		n := &ClusterPolicyController{operatorNamespace: r.OperatorNamespace}
+		err := n.cleanup(ctx, r)
+		if err != nil {
+			r.Log.Error(err, "Failed to clean up the cluster")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	} else {
+		r.Log.Info("ClusterPolicy deleted, skipping cleanup as another instance takes over", "name", instance.ObjectMeta.Name, "next", next.ObjectMeta.Name)
+	}
+
+	r.resetActive(instance.ObjectMeta.Name)
+
+	controllerutil.RemoveFinalizer(instance, clusterPolicyFinalizer)
+	err := r.Client.Update(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, "Failed to remove finalizer from ClusterPolicy")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// getActiveClusterPolicy returns the ClusterPolicy to be reconciled, see
+// ActiveClusterPolicy. nil is returned when no such instance exists.
+func getActiveClusterPolicy(ctx context.Context, r *ClusterPolicyReconciler) (*gpuv1.ClusterPolicy, error) {
+	list := &gpuv1.ClusterPolicyList{}
+	err := r.Client.List(ctx, list)
+	if err != nil {
+		r.Log.Error(err, "Unable to list ClusterPolicies")
+		return nil, err
+	}
+	return ActiveClusterPolicy(list.Items), nil
+}
+
+// ActiveClusterPolicy returns the ClusterPolicy the operator reconciles among
+// items: the oldest instance not being deleted, with the name used as a
+// tie-breaker. All other instances are ignored. nil is returned when no such
+// instance exists.
+func ActiveClusterPolicy(items []gpuv1.ClusterPolicy) *gpuv1.ClusterPolicy {
+	var active *gpuv1.ClusterPolicy
+	for i := range items {
+		cp := &items[i]
+		if cp.ObjectMeta.DeletionTimestamp != nil {
+			continue
+		}
+		if active == nil || isOlderClusterPolicy(cp, active) {
+			active = cp
+		}
+	}
+	return active
+}
+
+func isOlderClusterPolicy(a, b *gpuv1.ClusterPolicy) bool {
+	if !a.ObjectMeta.CreationTimestamp.Equal(&b.ObjectMeta.CreationTimestamp) {
+		return a.ObjectMeta.CreationTimestamp.Before(&b.ObjectMeta.CreationTimestamp)
+	}
+	return a.ObjectMeta.Name < b.ObjectMeta.Name
+}
+
+// updateIgnoredCRState persists the ignored state on a ClusterPolicy which
+// is not the active one
+func updateIgnoredCRState(ctx context.Context, r *ClusterPolicyReconciler, instance *gpuv1.ClusterPolicy, activeName string) error {
+	oldStatus := instance.Status.DeepCopy()
+
+	instance.SetStatus(gpuv1.Ignored, r.OperatorNamespace)
+	instance.Status.States = nil
+	msg := fmt.Sprintf("ClusterPolicy %s is active, only one ClusterPolicy is reconciled", activeName)
+	for _, conditionType := range []string{gpuv1.ConditionReady, gpuv1.ConditionProgressing, gpuv1.ConditionDegraded} {
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Ignored",
+			Message:            msg,
+			ObservedGeneration: instance.Generation,
+		})
+	}
+
+	if equality.Semantic.DeepEqual(oldStatus, &instance.Status) {
+		// status is unchanged
+		return nil
+	}
+	err := r.Client.Status().Update(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, "Failed to update ClusterPolicy status")
+		return err
+	}
+	return nil
+}
+
+// newStateStatus converts the result of a single state into a StateStatus entry
+func newStateStatus(name string, state gpuv1.State, err error) gpuv1.StateStatus {
+	ss := gpuv1.StateStatus{
+		Name:  name,
+		State: state,
+	}
+
+	switch {
+	case err != nil:
+		ss.State = gpuv1.NotReady
+		ss.Reason = reasonReconcileFailed
+		ss.Message = err.Error()
+	case state == gpuv1.Ready:
+		ss.Reason = "Ready"
+		ss.Message = "All resources of the state are ready"
+	case state == gpuv1.Disabled:
+		ss.Reason = "Disabled"
+		ss.Message = "The state is disabled in the ClusterPolicy"
+	case state == gpuv1.Paused:
+		ss.Reason = "Paused"
+		ss.Message = "Reconciliation of the state is paused in the ClusterPolicy"
+	case state == gpuv1.Skipped:
+		ss.Reason = "DependenciesNotReady"
+		ss.Message = "The states it depends on are not ready"
+	default:
+		ss.State = gpuv1.NotReady
+		ss.Reason = "ResourcesNotReady"
+		ss.Message = "One or more resources of the state are not ready"
+	}
+
+	return ss
+}
+
+// stateStatus converts the result of the reconciliation of a state into a StateStatus entry
+func (s stateResult) stateStatus() gpuv1.StateStatus {
+	ss := newStateStatus(s.name, s.status, s.err)
+	if ss.State == gpuv1.Skipped {
+		ss.Message = fmt.Sprintf("The states it depends on are not ready: %s", strings.Join(s.blockedBy, ", "))
+	}
+	return ss
+}
+
+// setClusterPolicyConditions derives the Ready, Progressing and Degraded
+// conditions from the per-state results of the last reconciliation
+func setClusterPolicyConditions(instance *gpuv1.ClusterPolicy, states []gpuv1.StateStatus, reconcileErr error) {
+	statesNotReady := []string{}
+	statesPaused := []string{}
+	for _, ss := range states {
+		switch ss.State {
+		case gpuv1.NotReady, gpuv1.Skipped:
+			statesNotReady = append(statesNotReady, ss.Name)
+		case gpuv1.Paused:
+			statesPaused = append(statesPaused, ss.Name)
+		}
+	}
+
+	ready := metav1.Condition{Type: gpuv1.ConditionReady, ObservedGeneration: instance.Generation}
+	progressing := metav1.Condition{Type: gpuv1.ConditionProgressing, ObservedGeneration: instance.Generation}
+	degraded := metav1.Condition{Type: gpuv1.ConditionDegraded, ObservedGeneration: instance.Generation}
+
+	switch {
+	case reconcileErr != nil:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, reasonReconcileFailed, reconcileErr.Error()
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionFalse, reasonReconcileFailed, reconcileErr.Error()
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, reasonReconcileFailed, reconcileErr.Error()
+	case len(statesNotReady) > 0:
+		msg := fmt.Sprintf("States not ready: %s", strings.Join(statesNotReady, ", "))
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "StatesNotReady", msg
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, "StatesNotReady", msg
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionFalse, "ReconcileSucceeded", "No errors during reconciliation"
+	default:
+		msg := "All enabled states are ready"
+		if len(statesPaused) > 0 {
+			msg = fmt.Sprintf("%s, states paused: %s", msg, strings.Join(statesPaused, ", "))
+		}
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionTrue, "AllStatesReady", msg
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionFalse, "AllStatesReady", msg
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionFalse, "ReconcileSucceeded", "No errors during reconciliation"
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, ready)
+	meta.SetStatusCondition(&instance.Status.Conditions, progressing)
+	meta.SetStatusCondition(&instance.Status.Conditions, degraded)
+}
+
 func addWatchNewGPUNode(ctx context.Context, r *ClusterPolicyReconciler, c controller.Controller, mgr ctrl.Manager) error {
 	// Define a mapping from the Node object in the event to one or more
 	// ClusterPolicy objects to Reconcile
@@ -305,13 +662,52 @@ func addWatchNewGPUNode(ctx context.Context, r *ClusterPolicyReconciler, c contr
 	return err
 }
 
+func addWatchClusterPolicyDeletion(ctx context.Context, r *ClusterPolicyReconciler, c controller.Controller, mgr ctrl.Manager) error {
+	mapFn := func(ctx context.Context, a client.Object) []reconcile.Request {
+		list := &gpuv1.ClusterPolicyList{}
+		err := r.List(ctx, list)
+		if err != nil {
+			r.Log.Error(err, "Unable to list ClusterPolicies")
+			return []reconcile.Request{}
+		}
+
+		cpToRec := []reconcile.Request{}
+		for _, cp := range list.Items {
+			if cp.ObjectMeta.GetName() == a.GetName() {
+				continue
+			}
+			cpToRec = append(cpToRec, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      cp.ObjectMeta.GetName(),
+				Namespace: cp.ObjectMeta.GetNamespace(),
+			}})
+		}
+		return cpToRec
+	}
+
+	p := predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+
+	return c.Watch(
+		source.Kind(mgr.GetCache(), &gpuv1.ClusterPolicy{}),
+		handler.EnqueueRequestsFromMapFunc(mapFn),
+		p)
+}
+
 // 监听三种资源的变化
 // 1. CRD 的 ClusterPolicy 变化.
 // 2. 节点标签发生变化
 // 3. 当 ds 发生变化
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterPolicyReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	// Create a new controller
+	// Create a new controller. Although the reconciler keeps no controller state
+	// across reconciliations, MaxConcurrentReconciles stays 1: the workqueue only
+	// serializes requests for the same ClusterPolicy, and while the active instance
+	// changes (e.g. the oldest one is deleted) the outgoing and the incoming
+	// ClusterPolicy would otherwise apply the same operands and node labels concurrently
 	c, err := controller.New("clusterpolicy-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: 1, RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(minDelayCR, maxDelayCR)})
 	if err != nil {
 		return err
@@ -323,6 +719,13 @@ func (r *ClusterPolicyReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		return err
 	}
 
+	// When a ClusterPolicy is deleted, requeue the remaining ones so that
+	// the next oldest instance takes over if the active one was removed
+	err = addWatchClusterPolicyDeletion(ctx, r, c, mgr)
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to Node labels and requeue the owner ClusterPolicy
 	err = addWatchNewGPUNode(ctx, r, c, mgr)
 	if err != nil {
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestSetClusterPolicyConditions(t *testing.T) {
+	testCases := []struct {
+		description         string
+		states              []gpuv1.StateStatus
+		reconcileErr        error
+		expectedReady       metav1.ConditionStatus
+		expectedProgressing metav1.ConditionStatus
+		expectedDegraded    metav1.ConditionStatus
+	}{
+		{
+			"all states ready",
+			[]gpuv1.StateStatus{
+				newStateStatus("pre-requisites", gpuv1.Ready, nil),
+				newStateStatus("state-device-plugin", gpuv1.Disabled, nil),
+			},
+			nil,
+			metav1.ConditionTrue,
+			metav1.ConditionFalse,
+			metav1.ConditionFalse,
+		},
+		{
+			"paused state does not block readiness",
+			[]gpuv1.StateStatus{
+				newStateStatus("pre-requisites", gpuv1.Ready, nil),
+				newStateStatus("state-device-plugin", gpuv1.Paused, nil),
+			},
+			nil,
+			metav1.ConditionTrue,
+			metav1.ConditionFalse,
+			metav1.ConditionFalse,
+		},
+		{
+			"one state not ready",
+			[]gpuv1.StateStatus{
+				newStateStatus("pre-requisites", gpuv1.Ready, nil),
+				newStateStatus("state-container-toolkit", gpuv1.NotReady, nil),
+			},
+			nil,
+			metav1.ConditionFalse,
+			metav1.ConditionTrue,
+			metav1.ConditionFalse,
+		},
+		{
+			"state skipped",
+			[]gpuv1.StateStatus{
+				newStateStatus("state-container-toolkit", gpuv1.NotReady, nil),
+				newStateStatus("state-device-plugin", gpuv1.Skipped, nil),
+			},
+			nil,
+			metav1.ConditionFalse,
+			metav1.ConditionTrue,
+			metav1.ConditionFalse,
+		},
+		{
+			"state failed with an error",
+			[]gpuv1.StateStatus{
+				newStateStatus("pre-requisites", gpuv1.NotReady, fmt.Errorf("failed")),
+			},
+			fmt.Errorf("failed"),
+			metav1.ConditionFalse,
+			metav1.ConditionFalse,
+			metav1.ConditionTrue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cp := &gpuv1.ClusterPolicy{}
+			cp.Generation = 3
+			setClusterPolicyConditions(cp, tc.states, tc.reconcileErr)
+
+			ready := meta.FindStatusCondition(cp.Status.Conditions, gpuv1.ConditionReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tc.expectedReady, ready.Status)
+			require.Equal(t, int64(3), ready.ObservedGeneration)
+
+			progressing := meta.FindStatusCondition(cp.Status.Conditions, gpuv1.ConditionProgressing)
+			require.NotNil(t, progressing)
+			require.Equal(t, tc.expectedProgressing, progressing.Status)
+
+			degraded := meta.FindStatusCondition(cp.Status.Conditions, gpuv1.ConditionDegraded)
+			require.NotNil(t, degraded)
+			require.Equal(t, tc.expectedDegraded, degraded.Status)
+		})
+	}
+}
+
+func TestSetStateStatus(t *testing.T) {
+	cp := &gpuv1.ClusterPolicy{}
+
+	cp.SetStateStatus(newStateStatus("state-device-plugin", gpuv1.NotReady, nil))
+	require.Len(t, cp.Status.States, 1)
+	transition := cp.Status.States[0].LastTransitionTime
+	require.False(t, transition.IsZero())
+
+	// same state, transition time must be preserved
+	cp.SetStateStatus(newStateStatus("state-device-plugin", gpuv1.NotReady, nil))
+	require.Len(t, cp.Status.States, 1)
+	require.Equal(t, transition, cp.Status.States[0].LastTransitionTime)
+
+	// new state, entry is updated in place
+	cp.SetStateStatus(newStateStatus("state-device-plugin", gpuv1.Ready, nil))
+	require.Len(t, cp.Status.States, 1)
+	require.Equal(t, gpuv1.Ready, cp.Status.States[0].State)
+	require.Equal(t, "Ready", cp.Status.States[0].Reason)
+}
+
+func TestGetActiveClusterPolicy(t *testing.T) {
+	ctx := context.Background()
+	r := newTestController(t).rec
+
+	older := &gpuv1.ClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "older-cluster-policy",
+			CreationTimestamp: metav1.NewTime(clusterPolicy.CreationTimestamp.Add(-time.Hour)),
+		},
+	}
+	err := r.Client.Create(ctx, older)
+	require.NoError(t, err)
+	defer r.Client.Delete(ctx, older) // nolint:errcheck
+
+	active, err := getActiveClusterPolicy(ctx, r)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	require.Equal(t, older.Name, active.Name)
+
+	cp := &gpuv1.ClusterPolicy{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: clusterPolicyName}, cp)
+	require.NoError(t, err)
+	err = updateIgnoredCRState(ctx, r, cp, active.Name)
+	require.NoError(t, err)
+
+	err = r.Client.Get(ctx, types.NamespacedName{Name: clusterPolicyName}, cp)
+	require.NoError(t, err)
+	require.Equal(t, gpuv1.Ignored, cp.Status.State)
+	ready := meta.FindStatusCondition(cp.Status.Conditions, gpuv1.ConditionReady)
+	require.NotNil(t, ready)
+	require.Equal(t, "Ignored", ready.Reason)
+
+	// once the older policy is gone, the sample policy becomes active again
+	err = r.Client.Delete(ctx, older)
+	require.NoError(t, err)
+	active, err = getActiveClusterPolicy(ctx, r)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	require.Equal(t, clusterPolicyName, active.Name)
+}
+
+func TestClusterPolicyCleanup(t *testing.T) {
+	ctx := context.Background()
+	n := newTestController(t)
+	n.operatorNamespace = "cleanup-test"
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   n.operatorNamespace,
+			Labels: map[string]string{podSecurityLabelPrefix + "enforce": "baseline"},
+		},
+	}
+	err := n.rec.Client.Create(ctx, ns)
+	require.NoError(t, err)
+	defer n.rec.Client.Delete(ctx, ns) // nolint:errcheck
+
+	err = n.setPodSecurityLabelsForNamespace()
+	require.NoError(t, err)
+
+	nodes := &corev1.NodeList{}
+	err = n.rec.Client.List(ctx, nodes)
+	require.NoError(t, err)
+	node := nodes.Items[0]
+	node.Annotations = map[string]string{driverAutoUpgradeAnnotationKey: "true"}
+	node.Labels[upgrade.GetUpgradeStateLabelKey()] = "upgrade-done"
+	err = n.rec.Client.Update(ctx, &node)
+	require.NoError(t, err)
+
+	err = n.cleanup(ctx, n.rec)
+	require.NoError(t, err)
+
+	err = n.rec.Client.List(ctx, nodes)
+	require.NoError(t, err)
+	for _, node := range nodes.Items {
+		for key := range node.Labels {
+			require.False(t, strings.HasPrefix(key, "xdxct.com/gpu."), "unexpected label %s on node %s", key, node.Name)
+		}
+		require.NotContains(t, node.Labels, upgrade.GetUpgradeStateLabelKey())
+		require.NotContains(t, node.Annotations, driverAutoUpgradeAnnotationKey)
+		// NFD labels are left untouched
+		require.Contains(t, node.Labels, nfdNvidiaPCILabelKey)
+	}
+
+	err = n.rec.Client.Get(ctx, types.NamespacedName{Name: ns.Name}, ns)
+	require.NoError(t, err)
+	require.Equal(t, "baseline", ns.Labels[podSecurityLabelPrefix+"enforce"])
+	require.NotContains(t, ns.Labels, podSecurityLabelPrefix+"audit")
+	require.NotContains(t, ns.Annotations, podSecurityOriginalLabelsAnnotationKey)
+}
+
+func TestClusterPolicyReconcilersIsolated(t *testing.T) {
+	ctx := context.Background()
+	// the sample ClusterPolicy leaves the images of the operands to the operator env
+	for _, env := range []string{"CONTAINER_TOOLKIT_IMAGE", "DEVICE_PLUGIN_IMAGE", "GFD_IMAGE", "VALIDATOR_IMAGE"} {
+		t.Setenv(env, "xdxct/"+strings.ToLower(env)+":test")
+	}
+
+	// every reconciler has its own cluster, operator namespace and metrics
+	namespaces := []string{"operator-a", "operator-b"}
+	reconcilers := make([]*ClusterPolicyReconciler, len(namespaces))
+	for i, namespace := range namespaces {
+		cl, err := newCluster(1, scheme.Scheme)
+		require.NoError(t, err)
+		err = cl.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+		require.NoError(t, err)
+		cp := clusterPolicy.DeepCopy()
+		cp.ResourceVersion = ""
+		err = cl.Create(ctx, cp)
+		require.NoError(t, err)
+
+		reconcilers[i] = &ClusterPolicyReconciler{
+			Client:            cl,
+			Log:               ctrl.Log.WithName("controller").WithName("ClusterPolicy").WithName(namespace),
+			Scheme:            scheme.Scheme,
+			OperatorNamespace: namespace,
+			Metrics:           newOperatorMetrics(),
+			k8sVersion:        "v1.28.0",
+		}
+	}
+
+	// reconciliations run concurrently, without sharing any state
+	var wg sync.WaitGroup
+	errs := make([]error, len(reconcilers))
+	for i, r := range reconcilers {
+		wg.Add(1)
+		go func(i int, r *ClusterPolicyReconciler) {
+			defer wg.Done()
+			_, errs[i] = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: clusterPolicyName}})
+		}(i, r)
+	}
+	wg.Wait()
+
+	for i, r := range reconcilers {
+		require.NoError(t, errs[i])
+
+		cp := &gpuv1.ClusterPolicy{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: clusterPolicyName}, cp)
+		require.NoError(t, err)
+		require.Equal(t, namespaces[i], cp.Status.Namespace)
+		require.NotEmpty(t, cp.Status.States)
+
+		daemonsets := &appsv1.DaemonSetList{}
+		err = r.Client.List(ctx, daemonsets)
+		require.NoError(t, err)
+		require.NotEmpty(t, daemonsets.Items)
+		for _, ds := range daemonsets.Items {
+			require.Equal(t, namespaces[i], ds.Namespace)
+		}
+		total := &dto.Metric{}
+		err = r.Metrics.reconciliationTotal.Write(total)
+		require.NoError(t, err)
+		require.Equal(t, float64(1), total.GetCounter().GetValue())
+	}
+
+	// the assets are loaded once per reconciler, and not modified by the reconciliations
+	states0, err := reconcilers[0].stateAssets()
+	require.NoError(t, err)
+	states1, err := reconcilers[1].stateAssets()
+	require.NoError(t, err)
+	require.NotSame(t, states0, states1)
+	require.Equal(t, states0.stateNames, states1.stateNames)
+}
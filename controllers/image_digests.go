@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/types/manifest"
+	"github.com/regclient/regclient/types/ref"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// imageDigestResolver resolves an image reference to the digest of its manifest
+type imageDigestResolver interface {
+	resolve(ctx context.Context, image string) (string, error)
+}
+
+// imageEnvNames are the env variables of the operands which pass an image to the pods
+// they spin off, pinned along with the images of the containers
+var imageEnvNames = map[string]bool{
+	ValidatorImageEnvName: true,
+}
+
+// registryDigestResolver resolves image digests through the registry API. The registry
+// client is created on the first resolution, with the credentials of the image pull
+// secrets of the ClusterPolicy and of the docker config of the operator, if mounted in
+// $DOCKER_CONFIG or ~/.docker, as well as the registry certificates in /etc/docker/certs.d.
+// Registries of the credentials whose address starts with http:// are accessed without TLS
+type registryDigestResolver struct {
+	reader      client.Reader
+	namespace   string
+	pullSecrets []string
+
+	once      sync.Once
+	client    *regclient.RegClient
+	clientErr error
+}
+
+func newRegistryDigestResolver(reader client.Reader, namespace string, pullSecrets []string) *registryDigestResolver {
+	return &registryDigestResolver{reader: reader, namespace: namespace, pullSecrets: pullSecrets}
+}
+
+// imagePullSecrets returns the image pull secrets of all operands of cp, without duplicates
+func imagePullSecrets(cp *gpuv1.ClusterPolicy) []string {
+	var secrets []string
+	seen := map[string]bool{}
+	for _, names := range [][]string{
+		cp.Spec.Operator.InitContainer.ImagePullSecrets,
+		cp.Spec.Driver.ImagePullSecrets,
+		cp.Spec.Driver.Manager.ImagePullSecrets,
+		cp.Spec.Toolkit.ImagePullSecrets,
+		cp.Spec.DevicePlugin.ImagePullSecrets,
+		cp.Spec.GPUFeatureDiscovery.ImagePullSecrets,
+		cp.Spec.NodeStatusExporter.ImagePullSecrets,
+		cp.Spec.Validator.ImagePullSecrets,
+	} {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				secrets = append(secrets, name)
+			}
+		}
+	}
+	return secrets
+}
+
+// dockerConfig is the content of the image pull secrets
+type dockerConfig struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// pullSecretHosts returns the registry settings holding the credentials of the image pull secret
+func pullSecretHosts(secret *corev1.Secret) ([]config.Host, error) {
+	auths := map[string]dockerAuth{}
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		dc := dockerConfig{}
+		if err := json.Unmarshal(data, &dc); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", corev1.DockerConfigJsonKey, err)
+		}
+		auths = dc.Auths
+	} else if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", corev1.DockerConfigKey, err)
+		}
+	} else {
+		return nil, fmt.Errorf("secret holds neither %s nor %s", corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
+	}
+
+	var hosts []config.Host
+	for _, name := range sortedAuthNames(auths) {
+		auth := auths[name]
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the credentials of %s: %v", name, err)
+			}
+			user, pass, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("the credentials of %s are not in the user:password format", name)
+			}
+			auth.Username, auth.Password = user, pass
+		}
+		h := config.HostNewName(name)
+		h.User = auth.Username
+		h.Pass = auth.Password
+		h.Token = auth.IdentityToken
+		hosts = append(hosts, *h)
+	}
+	return hosts, nil
+}
+
+func sortedAuthNames(auths map[string]dockerAuth) []string {
+	names := make([]string, 0, len(auths))
+	for name := range auths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registryClient returns the registry client, created on its first use
+func (r *registryDigestResolver) registryClient(ctx context.Context) (*regclient.RegClient, error) {
+	r.once.Do(func() {
+		var hosts []config.Host
+		for _, name := range r.pullSecrets {
+			secret := &corev1.Secret{}
+			err := r.reader.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: name}, secret)
+			if err != nil {
+				r.clientErr = fmt.Errorf("failed to get image pull secret %s: %v", name, err)
+				return
+			}
+			secretHosts, err := pullSecretHosts(secret)
+			if err != nil {
+				r.clientErr = fmt.Errorf("invalid image pull secret %s: %v", name, err)
+				return
+			}
+			hosts = append(hosts, secretHosts...)
+		}
+		// the credentials of the pull secrets take precedence over those of the docker config
+		r.client = regclient.New(regclient.WithDockerCreds(), regclient.WithDockerCerts(), regclient.WithConfigHost(hosts...))
+	})
+	return r.client, r.clientErr
+}
+
+func (r *registryDigestResolver) resolve(ctx context.Context, image string) (string, error) {
+	imageRef, err := ref.New(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to construct an image reference: %v", err)
+	}
+	rc, err := r.registryClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// a HEAD request is sufficient for most registries, fall back to a GET
+	// when the registry does not return the digest with the headers
+	m, err := rc.ManifestHead(ctx, imageRef)
+	if err == nil && manifest.GetDigest(m) != "" {
+		return manifest.GetDigest(m).String(), nil
+	}
+	m, err = rc.ManifestGet(ctx, imageRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to get image manifest: %v", err)
+	}
+	return manifest.GetDigest(m).String(), nil
+}
+
+// imageDigests caches the digests operand images were pinned to for a single
+// ClusterPolicy generation, so that every node runs exactly the same image.
+// Copies of the controller share the cache, it is updated by states running concurrently
+type imageDigests struct {
+	generation int64
+	// mu guards digests
+	mu      *sync.Mutex
+	digests map[string]string
+}
+
+func newImageDigests(generation int64) imageDigests {
+	return imageDigests{generation: generation, mu: &sync.Mutex{}, digests: map[string]string{}}
+}
+
+// get returns the digest image was pinned to, if any
+func (d imageDigests) get(image string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	digest, ok := d.digests[image]
+	return digest, ok
+}
+
+// set records the digest image is pinned to
+func (d imageDigests) set(image, digest string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.digests[image] = digest
+}
+
+// cachedImageDigests returns a copy of the image digests pinned by the last reconciliation
+func (r *ClusterPolicyReconciler) cachedImageDigests() imageDigests {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.imageDigests.copy()
+}
+
+// cacheImageDigests keeps the image digests pinned by a reconciliation for the next ones
+func (r *ClusterPolicyReconciler) cacheImageDigests(d imageDigests) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.imageDigests = d.copy()
+}
+
+func (d imageDigests) copy() imageDigests {
+	if d.digests == nil {
+		return imageDigests{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := newImageDigests(d.generation)
+	for image, digest := range d.digests {
+		c.digests[image] = digest
+	}
+	return c
+}
+
+// resetImageDigests drops the cached digests when the ClusterPolicy generation changed.
+// Digests recorded in the status for the current generation are reused, so that
+// restarting the operator does not resolve the images again
+func (n *ClusterPolicyController) resetImageDigests(clusterPolicy *gpuv1.ClusterPolicy) {
+	if !clusterPolicy.Spec.Operator.IsPinImageDigestsEnabled() {
+		n.imageDigests = imageDigests{}
+		return
+	}
+	if n.digestResolver == nil {
+		n.digestResolver = newRegistryDigestResolver(n.rec.apiReader(), n.operatorNamespace, imagePullSecrets(clusterPolicy))
+	}
+	if n.imageDigests.digests != nil && n.imageDigests.generation == clusterPolicy.Generation {
+		return
+	}
+
+	n.imageDigests = newImageDigests(clusterPolicy.Generation)
+	if clusterPolicy.Status.ImageDigestsGeneration != clusterPolicy.Generation {
+		return
+	}
+	for _, d := range clusterPolicy.Status.ImageDigests {
+		n.imageDigests.digests[d.Image] = d.Digest
+	}
+}
+
+// pinImage returns image pinned to its digest, resolving it once per ClusterPolicy generation
+func (n ClusterPolicyController) pinImage(image string) (string, error) {
+	if image == "" || strings.Contains(image, "@") {
+		// already pinned to a digest
+		return image, nil
+	}
+	if n.imageDigests.digests == nil || n.digestResolver == nil {
+		return "", fmt.Errorf("image digests are not initialized")
+	}
+
+	digest, ok := n.imageDigests.get(image)
+	if !ok {
+		var err error
+		digest, err = n.digestResolver.resolve(n.ctx, image)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve digest of image %s: %v", image, err)
+		}
+		n.imageDigests.set(image, digest)
+	}
+	return image + "@" + digest, nil
+}
+
+// pinImageDigests pins the images of all containers of podSpec to their digests,
+// including the images passed through env to the pods spun off by the validator
+func (n ClusterPolicyController) pinImageDigests(podSpec *corev1.PodSpec) error {
+	pinContainer := func(c *corev1.Container) error {
+		image, err := n.pinImage(c.Image)
+		if err != nil {
+			return err
+		}
+		c.Image = image
+		for i, env := range c.Env {
+			if !imageEnvNames[env.Name] || env.ValueFrom != nil {
+				continue
+			}
+			value, err := n.pinImage(env.Value)
+			if err != nil {
+				return err
+			}
+			c.Env[i].Value = value
+		}
+		return nil
+	}
+
+	for i := range podSpec.InitContainers {
+		if err := pinContainer(&podSpec.InitContainers[i]); err != nil {
+			return err
+		}
+	}
+	for i := range podSpec.Containers {
+		if err := pinContainer(&podSpec.Containers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getImageDigestsStatus returns the image digests resolved for the given generation, sorted by image
+func (n *ClusterPolicyController) getImageDigestsStatus(generation int64) []gpuv1.ImageDigestStatus {
+	if n.imageDigests.digests == nil || n.imageDigests.generation != generation {
+		return nil
+	}
+	n.imageDigests.mu.Lock()
+	defer n.imageDigests.mu.Unlock()
+	status := []gpuv1.ImageDigestStatus{}
+	for image, digest := range n.imageDigests.digests {
+		status = append(status, gpuv1.ImageDigestStatus{Image: image, Digest: digest})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Image < status[j].Image })
+	return status
+}
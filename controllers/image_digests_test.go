@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/regclient/regclient/config"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeDigestResolver struct {
+	digests map[string]string
+	calls   int
+}
+
+func (r *fakeDigestResolver) resolve(ctx context.Context, image string) (string, error) {
+	r.calls++
+	digest, ok := r.digests[image]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown")
+	}
+	return digest, nil
+}
+
+func TestPinImageDigests(t *testing.T) {
+	resolver := &fakeDigestResolver{digests: map[string]string{
+		"nvcr.io/nvidia/cuda:12.2.0-base-ubi8":                    "sha256:1111",
+		"nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0": "sha256:2222",
+	}}
+	cp := &gpuv1.ClusterPolicy{}
+	cp.Generation = 2
+	cp.Spec.Operator.PinImageDigests = boolTrue
+
+	n := ClusterPolicyController{ctx: context.Background(), digestResolver: resolver}
+	n.resetImageDigests(cp)
+
+	podSpec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Name:  "plugin-validation",
+			Image: "nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0",
+			Env:   []corev1.EnvVar{{Name: ValidatorImageEnvName, Value: "nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0"}},
+		}},
+		Containers: []corev1.Container{
+			{Name: "main", Image: "nvcr.io/nvidia/cuda:12.2.0-base-ubi8", Env: []corev1.EnvVar{{Name: "RHCOS_IMAGE_MISSING", Value: "true"}}},
+			{Name: "pinned", Image: "nvcr.io/nvidia/cuda@sha256:3333"},
+		},
+	}
+	err := n.pinImageDigests(podSpec)
+	require.NoError(t, err)
+	require.Equal(t, "nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0@sha256:2222", podSpec.InitContainers[0].Image)
+	require.Equal(t, "nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0@sha256:2222", podSpec.InitContainers[0].Env[0].Value)
+	require.Equal(t, "nvcr.io/nvidia/cuda:12.2.0-base-ubi8@sha256:1111", podSpec.Containers[0].Image)
+	require.Equal(t, "true", podSpec.Containers[0].Env[0].Value)
+	require.Equal(t, "nvcr.io/nvidia/cuda@sha256:3333", podSpec.Containers[1].Image)
+	// every image is resolved only once per generation
+	require.Equal(t, 2, resolver.calls)
+
+	status := n.getImageDigestsStatus(cp.Generation)
+	require.Equal(t, []gpuv1.ImageDigestStatus{
+		{Image: "nvcr.io/nvidia/cloud-native/gpu-operator-validator:v1.0", Digest: "sha256:2222"},
+		{Image: "nvcr.io/nvidia/cuda:12.2.0-base-ubi8", Digest: "sha256:1111"},
+	}, status)
+
+	// digests recorded in status are reused after an operator restart
+	cp.Status.ImageDigests = status
+	cp.Status.ImageDigestsGeneration = cp.Generation
+	n = ClusterPolicyController{ctx: context.Background(), digestResolver: resolver}
+	n.resetImageDigests(cp)
+	_, err = n.pinImage("nvcr.io/nvidia/cuda:12.2.0-base-ubi8")
+	require.NoError(t, err)
+	require.Equal(t, 2, resolver.calls)
+
+	// a new generation resolves the images again
+	cp.Generation = 3
+	n.resetImageDigests(cp)
+	_, err = n.pinImage("nvcr.io/nvidia/cuda:12.2.0-base-ubi8")
+	require.NoError(t, err)
+	require.Equal(t, 3, resolver.calls)
+
+	// unresolvable images are reported as errors
+	_, err = n.pinImage("nvcr.io/nvidia/unknown:v1")
+	require.Error(t, err)
+}
+
+func TestPullSecretHosts(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          map[string][]byte
+		errorExpected bool
+		expected      []config.Host
+	}{
+		{
+			description: "dockerconfigjson",
+			data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {
+				"registry.example.com": {"auth": "dXNlcjpwYXNz"},
+				"http://registry.local:5000": {"username": "admin", "password": "secret"}
+			}}`)},
+			expected: []config.Host{
+				{Name: "registry.local:5000", Hostname: "registry.local:5000", CredHost: "http://registry.local:5000", TLS: config.TLSDisabled, User: "admin", Pass: "secret"},
+				{Name: "registry.example.com", Hostname: "registry.example.com", TLS: config.TLSEnabled, User: "user", Pass: "pass"},
+			},
+		},
+		{
+			description: "dockercfg",
+			data:        map[string][]byte{corev1.DockerConfigKey: []byte(`{"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}}`)},
+			expected: []config.Host{
+				{Name: "docker.io", Hostname: "registry-1.docker.io", CredHost: "https://index.docker.io/v1/", TLS: config.TLSEnabled, User: "user", Pass: "pass"},
+			},
+		},
+		{
+			description:   "invalid auth",
+			data:          map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dXNlcg=="}}}`)},
+			errorExpected: true,
+		},
+		{
+			description:   "not a pull secret",
+			data:          map[string][]byte{"password": []byte("secret")},
+			errorExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			hosts, err := pullSecretHosts(&corev1.Secret{Data: tc.data})
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, hosts, len(tc.expected))
+			for i, expected := range tc.expected {
+				require.Equal(t, expected.Name, hosts[i].Name)
+				require.Equal(t, expected.Hostname, hosts[i].Hostname)
+				require.Equal(t, expected.CredHost, hosts[i].CredHost)
+				require.Equal(t, expected.TLS, hosts[i].TLS)
+				require.Equal(t, expected.User, hosts[i].User)
+				require.Equal(t, expected.Pass, hosts[i].Pass)
+			}
+		})
+	}
+}
+
+func TestRegistryDigestResolverPullSecrets(t *testing.T) {
+	cp := &gpuv1.ClusterPolicy{}
+	cp.Spec.Driver.ImagePullSecrets = []string{"registry-secret"}
+	cp.Spec.DevicePlugin.ImagePullSecrets = []string{"registry-secret", "missing-secret"}
+	require.Equal(t, []string{"registry-secret", "missing-secret"}, imagePullSecrets(cp))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-secret", Namespace: "gpu-operator"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`)},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	r := newRegistryDigestResolver(c, "gpu-operator", []string{"registry-secret"})
+	rc, err := r.registryClient(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, rc)
+
+	r = newRegistryDigestResolver(c, "gpu-operator", imagePullSecrets(cp))
+	_, err = r.resolve(context.Background(), "registry.example.com/driver:v1")
+	require.ErrorContains(t, err, "missing-secret")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
@@ -17,22 +18,24 @@ import (
 	"github.com/mitchellh/hashstructure"
 	apiconfigv1 "github.com/openshift/api/config/v1"
 	apiimagev1 "github.com/openshift/api/image/v1"
-	secv1 "github.com/openshift/api/security/v1"
-	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"golang.org/x/mod/semver"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
 	nodev1beta1 "k8s.io/api/node/v1beta1"
-	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -103,6 +106,8 @@ const (
 	MetricsConfigMountPath = "/etc/dcgm-exporter/" + MetricsConfigFileName
 	// MetricsConfigFileName indicates custom dcgm metrics file name
 	MetricsConfigFileName = "dcgm-metrics.csv"
+	// FieldManager is the field manager the operator applies the operand objects with
+	FieldManager = "xdxct-gpu-operator"
 	// NvidiaAnnotationHashKey indicates annotation name for last applied hash by gpu-operator
 	NvidiaAnnotationHashKey = "xdxct.com/last-applied-hash"
 	// NvidiaDisableRequireEnvName is the env name to disable default cuda constraints
@@ -248,6 +253,70 @@ var SubscriptionPathMap = map[string](MountPathToVolumeSource){
 
 type controlFunc []func(n ClusterPolicyController) (gpuv1.State, error)
 
+// csaFieldManagers are the field managers of the objects written by the releases of
+// the operator which created and updated them without server-side apply: the name of
+// the operator binary, and the manager the API server assigns to the fields of an
+// object without managed fields when it is first applied
+var csaFieldManagers = []string{"gpu-operator", "before-first-apply"}
+
+// upgradeManagedFields moves the fields of obj owned by csaFieldManagers to
+// FieldManager, if obj exists and has any. Otherwise the fields the operator stops
+// setting would still be owned by the old managers, and never be removed
+func upgradeManagedFields(n ClusterPolicyController, obj client.Object) error {
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("%T is not a client.Object", obj)
+	}
+	err := n.rec.Client.Get(n.ctx, client.ObjectKeyFromObject(obj), existing)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(existing, sets.New(csaFieldManagers...), FieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	n.rec.Log.Info("Moving the fields of the object to the server-side apply field manager", "Name", obj.GetName(), "Namespace", obj.GetNamespace())
+	return n.rec.Client.Patch(n.ctx, existing, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// serverSideApply applies obj with server-side apply as FieldManager, which creates
+// it if needed. The operator forces the ownership of the fields it sets, while the
+// fields it leaves unset, such as annotations added by other controllers, are kept.
+// obj is updated with the object returned by the API server
+func serverSideApply(n ClusterPolicyController, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, n.rec.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := upgradeManagedFields(n, obj); err != nil {
+		return fmt.Errorf("failed to upgrade the managed fields of %s %s: %v", gvk.Kind, obj.GetName(), err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	applied := &unstructured.Unstructured{Object: content}
+	applied.SetGroupVersionKind(gvk)
+	// the zero values of these fields would otherwise be applied as owned by the operator
+	unstructured.RemoveNestedField(applied.Object, "status")
+	unstructured.RemoveNestedField(applied.Object, "metadata", "creationTimestamp")
+	applied.SetResourceVersion("")
+	applied.SetManagedFields(nil)
+
+	err = n.rec.Client.Patch(n.ctx, applied, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = applied.Object
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, obj)
+}
+
 // ServiceAccount creates ServiceAccount resource
 func ServiceAccount(n ClusterPolicyController) (gpuv1.State, error) {
 	ctx := n.ctx
@@ -271,13 +340,8 @@ func ServiceAccount(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, skipping update")
-			return gpuv1.Ready, nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
@@ -306,21 +370,10 @@ func Role(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, updating...")
-			err = n.rec.Client.Update(ctx, obj)
-			if err != nil {
-				logger.Info("Couldn't update", "Error", err)
-				return gpuv1.NotReady, err
-			}
-			return gpuv1.Ready, nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
-
 	return gpuv1.Ready, nil
 }
 
@@ -357,21 +410,10 @@ func RoleBinding(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, updating...")
-			err = n.rec.Client.Update(ctx, obj)
-			if err != nil {
-				logger.Info("Couldn't update", "Error", err)
-				return gpuv1.NotReady, err
-			}
-			return gpuv1.Ready, nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
-
 	return gpuv1.Ready, nil
 }
 
@@ -398,21 +440,10 @@ func ClusterRole(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, updating...")
-			err = n.rec.Client.Update(ctx, obj)
-			if err != nil {
-				logger.Info("Couldn't update", "Error", err)
-				return gpuv1.NotReady, err
-			}
-			return gpuv1.Ready, nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
-
 	return gpuv1.Ready, nil
 }
 
@@ -443,21 +474,10 @@ func ClusterRoleBinding(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, updating...")
-			err = n.rec.Client.Update(ctx, obj)
-			if err != nil {
-				logger.Info("Couldn't update", "Error", err)
-				return gpuv1.NotReady, err
-			}
-			return gpuv1.Ready, nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
-
 	return gpuv1.Ready, nil
 }
 
@@ -484,20 +504,10 @@ func createConfigMap(n ClusterPolicyController, configMapIdx int) (gpuv1.State,
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if !errors.IsAlreadyExists(err) {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-
-		logger.Info("Found Resource, updating...")
-		err = n.rec.Client.Update(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't update", "Error", err)
-			return gpuv1.NotReady, err
-		}
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
+		return gpuv1.NotReady, err
 	}
-
 	return gpuv1.Ready, nil
 }
 
@@ -617,18 +627,20 @@ func kernelFullVersion(n ClusterPolicyController) (string, string, string) {
 	return kFVersion, osTag, osVersion
 }
 
+// daemonsetTransformations maps the names of the DaemonSet assets to their transformation
+var daemonsetTransformations = map[string]func(*appsv1.DaemonSet, *gpuv1.ClusterPolicySpec, ClusterPolicyController) error{
+	"nvidia-driver-daemonset":           TransformDriver,
+	"xdxct-container-toolkit-daemonset": TransformToolkit,
+	"xdxct-device-plugin-daemonset":     TransformDevicePlugin,
+	"nvidia-node-status-exporter":       TransformNodeStatusExporter,
+	"gpu-feature-discovery":             TransformGPUDiscoveryPlugin,
+	"nvidia-operator-validator":         TransformValidator,
+}
+
 func preProcessDaemonSet(obj *appsv1.DaemonSet, n ClusterPolicyController) error {
 	logger := n.rec.Log.WithValues("Daemonset", obj.Name)
-	transformations := map[string]func(*appsv1.DaemonSet, *gpuv1.ClusterPolicySpec, ClusterPolicyController) error{
-		"nvidia-driver-daemonset":           TransformDriver,
-		"xdxct-container-toolkit-daemonset": TransformToolkit,
-		"xdxct-device-plugin-daemonset":     TransformDevicePlugin,
-		"nvidia-node-status-exporter":       TransformNodeStatusExporter,
-		"gpu-feature-discovery":             TransformGPUDiscoveryPlugin,
-		"nvidia-operator-validator":         TransformValidator,
-	}
 
-	t, ok := transformations[obj.Name]
+	t, ok := daemonsetTransformations[obj.Name]
 	if !ok {
 		logger.Info(fmt.Sprintf("No transformation for Daemonset '%s'", obj.Name))
 		return nil
@@ -641,6 +653,13 @@ func preProcessDaemonSet(obj *appsv1.DaemonSet, n ClusterPolicyController) error
 		return err
 	}
 
+	// apply per component scheduling configuration on top of the common one
+	err = applyComponentSchedulingConfig(obj, &n.singleton.Spec, getComponentSchedulingSpec(obj.Name, &n.singleton.Spec))
+	if err != nil {
+		logger.Error(err, "Failed to apply component scheduling configuration", "resource", obj.Name)
+		return err
+	}
+
 	// apply per operand Daemonset config
 	err = t(obj, &n.singleton.Spec, n)
 	if err != nil {
@@ -651,6 +670,15 @@ func preProcessDaemonSet(obj *appsv1.DaemonSet, n ClusterPolicyController) error
 	// apply custom Labels and Annotations to the podSpec if any
 	applyCommonDaemonsetMetadata(obj, &n.singleton.Spec.Daemonsets)
 
+	// pin all images to their digests if requested
+	if n.singleton.Spec.Operator.IsPinImageDigestsEnabled() {
+		err = n.pinImageDigests(&obj.Spec.Template.Spec)
+		if err != nil {
+			logger.Error(err, "Failed to pin image digests", "resource", obj.Name)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -685,7 +713,7 @@ func applyCommonDaemonsetMetadata(obj *appsv1.DaemonSet, dsSpec *gpuv1.Daemonset
 // Apply common config that is applicable for all Daemonsets
 func applyCommonDaemonsetConfig(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec) error {
 	// apply daemonset update strategy
-	err := applyUpdateStrategyConfig(obj, config)
+	err := applyUpdateStrategyConfig(obj, config.Daemonsets.UpdateStrategy, config.Daemonsets.RollingUpdate)
 	if err != nil {
 		return err
 	}
@@ -702,6 +730,83 @@ func applyCommonDaemonsetConfig(obj *appsv1.DaemonSet, config *gpuv1.ClusterPoli
 	return nil
 }
 
+// getComponentSchedulingSpec returns the per-component scheduling configuration
+// for the given operand Daemonset, or nil if there is none
+func getComponentSchedulingSpec(name string, config *gpuv1.ClusterPolicySpec) *gpuv1.SchedulingSpec {
+	switch name {
+	case "nvidia-driver-daemonset":
+		return &config.Driver.SchedulingSpec
+	case "xdxct-container-toolkit-daemonset":
+		return &config.Toolkit.SchedulingSpec
+	case "xdxct-device-plugin-daemonset":
+		return &config.DevicePlugin.SchedulingSpec
+	case "nvidia-node-status-exporter":
+		return &config.NodeStatusExporter.SchedulingSpec
+	case "gpu-feature-discovery":
+		return &config.GPUFeatureDiscovery.SchedulingSpec
+	case "nvidia-operator-validator":
+		return &config.Validator.SchedulingSpec
+	}
+	return nil
+}
+
+// applyComponentSchedulingConfig merges the per-component scheduling configuration
+// on top of the common Daemonset configuration already applied to obj
+func applyComponentSchedulingConfig(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, sched *gpuv1.SchedulingSpec) error {
+	if sched == nil {
+		return nil
+	}
+
+	// unset component update strategy fields fall back to the common settings
+	if sched.UpdateStrategy != "" || sched.RollingUpdate != nil {
+		updateStrategy := sched.UpdateStrategy
+		if updateStrategy == "" {
+			updateStrategy = config.Daemonsets.UpdateStrategy
+		}
+		rollingUpdate := sched.RollingUpdate
+		if rollingUpdate == nil {
+			rollingUpdate = config.Daemonsets.RollingUpdate
+		}
+		err := applyUpdateStrategyConfig(obj, updateStrategy, rollingUpdate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sched.PriorityClassName != "" {
+		obj.Spec.Template.Spec.PriorityClassName = sched.PriorityClassName
+	}
+
+	// component tolerations are added to the common ones
+	for _, toleration := range sched.Tolerations {
+		found := false
+		for _, existing := range obj.Spec.Template.Spec.Tolerations {
+			if reflect.DeepEqual(existing, toleration) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			obj.Spec.Template.Spec.Tolerations = append(obj.Spec.Template.Spec.Tolerations, toleration)
+		}
+	}
+
+	if len(sched.NodeSelector) > 0 {
+		if obj.Spec.Template.Spec.NodeSelector == nil {
+			obj.Spec.Template.Spec.NodeSelector = make(map[string]string)
+		}
+		for key, value := range sched.NodeSelector {
+			obj.Spec.Template.Spec.NodeSelector[key] = value
+		}
+	}
+
+	if sched.Affinity != nil {
+		obj.Spec.Template.Spec.Affinity = sched.Affinity.DeepCopy()
+	}
+
+	return nil
+}
+
 // TransformGPUDiscoveryPlugin transforms GPU discovery daemonset with required config as per ClusterPolicy
 func TransformGPUDiscoveryPlugin(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, n ClusterPolicyController) error {
 	// update validation container
@@ -711,7 +816,7 @@ func TransformGPUDiscoveryPlugin(obj *appsv1.DaemonSet, config *gpuv1.ClusterPol
 	}
 
 	// update image
-	img, err := gpuv1.ImagePath(&config.GPUFeatureDiscovery)
+	img, err := gpuv1.ImagePath(&config.GPUFeatureDiscovery, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -744,7 +849,7 @@ func TransformGPUDiscoveryPlugin(obj *appsv1.DaemonSet, config *gpuv1.ClusterPol
 	// set/append environment variables for exporter container
 	if len(config.GPUFeatureDiscovery.Env) > 0 {
 		for _, env := range config.GPUFeatureDiscovery.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[0]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[0]), env)
 		}
 	}
 
@@ -796,7 +901,7 @@ func TransformDriver(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, n C
 	}
 
 	// update driver-manager initContainer
-	err = transformDriverManagerInitContainer(obj, &config.Driver.Manager)
+	err = transformDriverManagerInitContainer(obj, &config.Driver.Manager, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -919,7 +1024,9 @@ func getOrCreateTrustedCAConfigMap(n ClusterPolicyController, name string) (*cor
 	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: configMap.ObjectMeta.Namespace, Name: configMap.ObjectMeta.Name}, found)
 	if err != nil && errors.IsNotFound(err) {
 		logger.Info("Not found, creating")
-		err = n.rec.Client.Create(ctx, configMap)
+		// the ConfigMap is only created, not applied, as the CA bundle injected into its
+		// data would be reset to the empty bundle it is created with
+		err = n.rec.Client.Create(ctx, configMap, client.FieldOwner(FieldManager))
 		if err != nil {
 			logger.Info("Couldn't create")
 			return nil, fmt.Errorf("failed to create trusted CA bundle config map %q: %s", name, err)
@@ -977,7 +1084,7 @@ func TransformToolkit(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, n
 		return err
 	}
 	// update image
-	image, err := gpuv1.ImagePath(&config.Toolkit)
+	image, err := gpuv1.ImagePath(&config.Toolkit, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1003,7 +1110,7 @@ func TransformToolkit(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, n
 	// set/append environment variables for toolkit container
 	if len(config.Toolkit.Env) > 0 {
 		for _, env := range config.Toolkit.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[0]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[0]), env)
 		}
 	}
 
@@ -1105,7 +1212,7 @@ func TransformDevicePlugin(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpe
 	}
 
 	// update image
-	image, err := gpuv1.ImagePath(&config.DevicePlugin)
+	image, err := gpuv1.ImagePath(&config.DevicePlugin, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1134,7 +1241,7 @@ func TransformDevicePlugin(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpe
 	// set/append environment variables for device-plugin container
 	if len(config.DevicePlugin.Env) > 0 {
 		for _, env := range config.DevicePlugin.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[0]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[0]), env)
 		}
 	}
 	// apply plugin configuration through ConfigMap if one is provided
@@ -1182,7 +1289,7 @@ func TransformValidator(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec,
 // TransformValidatorShared applies general transformations to the validator daemonset with required config as per ClusterPolicy
 func TransformValidatorShared(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec, n ClusterPolicyController) error {
 	// update image
-	image, err := gpuv1.ImagePath(&config.Validator)
+	image, err := gpuv1.ImagePath(&config.Validator, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1210,7 +1317,7 @@ func TransformValidatorShared(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicy
 	// set/append environment variables for validator container
 	if len(config.Validator.Env) > 0 {
 		for _, env := range config.Validator.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[0]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[0]), env)
 		}
 	}
 
@@ -1225,7 +1332,7 @@ func TransformValidatorComponent(config *gpuv1.ClusterPolicySpec, podSpec *corev
 			continue
 		}
 		// update validation image
-		image, err := gpuv1.ImagePath(&config.Validator)
+		image, err := gpuv1.ImagePath(&config.Validator, config.Operator.ImageRegistryMirrors)
 		if err != nil {
 			return err
 		}
@@ -1239,7 +1346,7 @@ func TransformValidatorComponent(config *gpuv1.ClusterPolicySpec, podSpec *corev
 			// set/append environment variables for plugin-validation container
 			if len(config.Validator.Plugin.Env) > 0 {
 				for _, env := range config.Validator.Plugin.Env {
-					setContainerEnv(&(podSpec.InitContainers[i]), env.Name, env.Value)
+					setContainerEnvVar(&(podSpec.InitContainers[i]), env)
 				}
 			}
 			// set additional env to indicate image, pullSecrets to spin-off plugin validation workload pod.
@@ -1257,7 +1364,7 @@ func TransformValidatorComponent(config *gpuv1.ClusterPolicySpec, podSpec *corev
 			// set/append environment variables for driver-validation container
 			if len(config.Validator.Driver.Env) > 0 {
 				for _, env := range config.Validator.Driver.Env {
-					setContainerEnv(&(podSpec.InitContainers[i]), env.Name, env.Value)
+					setContainerEnvVar(&(podSpec.InitContainers[i]), env)
 				}
 			}
 		case "nvidia-fs":
@@ -1268,7 +1375,7 @@ func TransformValidatorComponent(config *gpuv1.ClusterPolicySpec, podSpec *corev
 			// set/append environment variables for toolkit-validation container
 			if len(config.Validator.Toolkit.Env) > 0 {
 				for _, env := range config.Validator.Toolkit.Env {
-					setContainerEnv(&(podSpec.InitContainers[i]), env.Name, env.Value)
+					setContainerEnvVar(&(podSpec.InitContainers[i]), env)
 				}
 			}
 		default:
@@ -1287,7 +1394,7 @@ func TransformNodeStatusExporter(obj *appsv1.DaemonSet, config *gpuv1.ClusterPol
 	}
 
 	// update image
-	image, err := gpuv1.ImagePath(&config.NodeStatusExporter)
+	image, err := gpuv1.ImagePath(&config.NodeStatusExporter, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1320,7 +1427,7 @@ func TransformNodeStatusExporter(obj *appsv1.DaemonSet, config *gpuv1.ClusterPol
 	// set/append environment variables for exporter container
 	if len(config.NodeStatusExporter.Env) > 0 {
 		for _, env := range config.NodeStatusExporter.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[0]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[0]), env)
 		}
 	}
 
@@ -1392,11 +1499,31 @@ func setContainerEnv(c *corev1.Container, key, value string) {
 		}
 
 		c.Env[i].Value = value
+		c.Env[i].ValueFrom = nil
 		return
 	}
 	c.Env = append(c.Env, corev1.EnvVar{Name: key, Value: value})
 }
 
+// setContainerEnvVar sets an environment variable configured in the ClusterPolicy,
+// either as a literal value or from a Secret, ConfigMap, field or resource reference
+func setContainerEnvVar(c *corev1.Container, env gpuv1.EnvVar) {
+	if env.ValueFrom == nil {
+		setContainerEnv(c, env.Name, env.Value)
+		return
+	}
+	envVar := corev1.EnvVar{Name: env.Name, ValueFrom: env.ValueFrom.DeepCopy()}
+	for i, val := range c.Env {
+		if val.Name != env.Name {
+			continue
+		}
+
+		c.Env[i] = envVar
+		return
+	}
+	c.Env = append(c.Env, envVar)
+}
+
 func getRuntimeClass(config *gpuv1.ClusterPolicySpec) string {
 	if config.Operator.RuntimeClass != "" {
 		return config.Operator.RuntimeClass
@@ -1487,7 +1614,7 @@ func handleDevicePluginConfig(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicy
 	// Apply custom configuration provided through ConfigMap
 	// setup env for main container
 	for i, container := range obj.Spec.Template.Spec.Containers {
-		if container.Name != "nvidia-device-plugin" && container.Name != "gpu-feature-discovery" {
+		if container.Name != "xdxct-device-plugin" && container.Name != "gpu-feature-discovery" {
 			continue
 		}
 		setContainerEnv(&obj.Spec.Template.Spec.Containers[i], "CONFIG_FILE", "/config/config.yaml")
@@ -1526,7 +1653,7 @@ func transformConfigManagerInitContainer(obj *appsv1.DaemonSet, config *gpuv1.Cl
 		// config-manager-init container is not added to the spec, this is a no-op
 		return nil
 	}
-	configManagerImage, err := gpuv1.ImagePath(&config.DevicePlugin)
+	configManagerImage, err := gpuv1.ImagePath(&config.DevicePlugin, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1538,6 +1665,9 @@ func transformConfigManagerInitContainer(obj *appsv1.DaemonSet, config *gpuv1.Cl
 	setContainerEnv(initContainer, "DEFAULT_CONFIG", config.DevicePlugin.Config.Default)
 	setContainerEnv(initContainer, "FALLBACK_STRATEGIES", "empty")
 
+	// append environment variables configured for the device-plugin
+	setConfigManagerEnv(initContainer, config.DevicePlugin.Env)
+
 	// setup volume mounts
 	addSharedMountsForPluginConfig(initContainer, config.DevicePlugin.Config)
 	return nil
@@ -1555,7 +1685,7 @@ func transformConfigManagerSidecarContainer(obj *appsv1.DaemonSet, config *gpuv1
 		// config-manager-init container is not added to the spec, this is a no-op
 		return nil
 	}
-	configManagerImage, err := gpuv1.ImagePath(&config.DevicePlugin)
+	configManagerImage, err := gpuv1.ImagePath(&config.DevicePlugin, config.Operator.ImageRegistryMirrors)
 	if err != nil {
 		return err
 	}
@@ -1567,12 +1697,31 @@ func transformConfigManagerSidecarContainer(obj *appsv1.DaemonSet, config *gpuv1
 	setContainerEnv(container, "DEFAULT_CONFIG", config.DevicePlugin.Config.Default)
 	setContainerEnv(container, "FALLBACK_STRATEGIES", "empty")
 
+	// append environment variables configured for the device-plugin
+	setConfigManagerEnv(container, config.DevicePlugin.Env)
+
 	// setup volume mounts
 	addSharedMountsForPluginConfig(container, config.DevicePlugin.Config)
 	return nil
 }
 
-func transformDriverManagerInitContainer(obj *appsv1.DaemonSet, driverManagerSpec *gpuv1.DriverManagerSpec) error {
+// setConfigManagerEnv appends the environment variables configured for the device-plugin
+// to a config-manager container. The variables the container already sets, from its asset
+// or the operator, configure the config-manager itself and are never overridden
+func setConfigManagerEnv(c *corev1.Container, env []gpuv1.EnvVar) {
+	owned := make(map[string]bool, len(c.Env))
+	for _, e := range c.Env {
+		owned[e.Name] = true
+	}
+	for _, e := range env {
+		if owned[e.Name] {
+			continue
+		}
+		setContainerEnvVar(c, e)
+	}
+}
+
+func transformDriverManagerInitContainer(obj *appsv1.DaemonSet, driverManagerSpec *gpuv1.DriverManagerSpec, mirrors []gpuv1.ImageRegistryMirror) error {
 	var container *corev1.Container
 	for i, initCtr := range obj.Spec.Template.Spec.InitContainers {
 		if initCtr.Name == "k8s-driver-manager" {
@@ -1585,7 +1734,7 @@ func transformDriverManagerInitContainer(obj *appsv1.DaemonSet, driverManagerSpe
 		return fmt.Errorf("failed to find k8s-driver-manager initContainer in spec")
 	}
 
-	managerImage, err := gpuv1.ImagePath(driverManagerSpec)
+	managerImage, err := gpuv1.ImagePath(driverManagerSpec, mirrors)
 	if err != nil {
 		return err
 	}
@@ -1598,7 +1747,7 @@ func transformDriverManagerInitContainer(obj *appsv1.DaemonSet, driverManagerSpe
 	// set/append environment variables for driver-manager initContainer
 	if len(driverManagerSpec.Env) > 0 {
 		for _, env := range driverManagerSpec.Env {
-			setContainerEnv(container, env.Name, env.Value)
+			setContainerEnvVar(container, env)
 		}
 	}
 
@@ -1821,15 +1970,16 @@ func resolveDriverTag(n ClusterPolicyController, driverSpec interface{}) (string
 				// use per kernel version tag
 				image = spec.Repository + "/" + spec.Image + ":" + spec.Version + "-" + n.currentKernelVersion
 			}
+			image = gpuv1.MirrorImage(image, n.singleton.Spec.Operator.ImageRegistryMirrors)
 		} else {
-			image, err = gpuv1.ImagePath(spec)
+			image, err = gpuv1.ImagePath(spec, n.singleton.Spec.Operator.ImageRegistryMirrors)
 			if err != nil {
 				return "", err
 			}
 		}
 	case *gpuv1.GPUDirectStorageSpec:
 		spec := driverSpec.(*gpuv1.GPUDirectStorageSpec)
-		image, err = gpuv1.ImagePath(spec)
+		image, err = gpuv1.ImagePath(spec, n.singleton.Spec.Operator.ImageRegistryMirrors)
 		if err != nil {
 			return "", err
 		}
@@ -1987,7 +2137,7 @@ func transformDriverContainer(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicy
 	// set/append environment variables for exporter container
 	if len(config.Driver.Env) > 0 {
 		for _, env := range config.Driver.Env {
-			setContainerEnv(&(obj.Spec.Template.Spec.Containers[driverIndex]), env.Name, env.Value)
+			setContainerEnvVar(&(obj.Spec.Template.Spec.Containers[driverIndex]), env)
 		}
 	}
 	// set container probe timeouts
@@ -2144,26 +2294,29 @@ func transformDriverContainer(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicy
 	return nil
 }
 
-func applyUpdateStrategyConfig(obj *appsv1.DaemonSet, config *gpuv1.ClusterPolicySpec) error {
-	switch config.Daemonsets.UpdateStrategy {
+func applyUpdateStrategyConfig(obj *appsv1.DaemonSet, updateStrategy string, rollingUpdate *gpuv1.RollingUpdateSpec) error {
+	switch updateStrategy {
 	case "OnDelete":
 		obj.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
 	case "RollingUpdate":
 		fallthrough
 	default:
-		// update config for RollingUpdate strategy
-		if config.Daemonsets.RollingUpdate == nil || config.Daemonsets.RollingUpdate.MaxUnavailable == "" {
-			return nil
-		}
 		if strings.HasPrefix(obj.Name, commonDriverDaemonsetName) {
 			// disallow setting RollingUpdate strategy with the driver container
 			return nil
 		}
+		// update config for RollingUpdate strategy
+		if rollingUpdate == nil || rollingUpdate.MaxUnavailable == "" {
+			if updateStrategy == "RollingUpdate" {
+				obj.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}
+			}
+			return nil
+		}
 		var intOrString intstr.IntOrString
-		if strings.HasSuffix(config.Daemonsets.RollingUpdate.MaxUnavailable, "%") {
-			intOrString = intstr.IntOrString{Type: intstr.String, StrVal: config.Daemonsets.RollingUpdate.MaxUnavailable}
+		if strings.HasSuffix(rollingUpdate.MaxUnavailable, "%") {
+			intOrString = intstr.IntOrString{Type: intstr.String, StrVal: rollingUpdate.MaxUnavailable}
 		} else {
-			int64Val, err := strconv.ParseInt(config.Daemonsets.RollingUpdate.MaxUnavailable, 10, 32)
+			int64Val, err := strconv.ParseInt(rollingUpdate.MaxUnavailable, 10, 32)
 			if err != nil {
 				return fmt.Errorf("failed to apply rolling update config: %s", err)
 			}
@@ -2201,7 +2354,7 @@ func transformValidationInitContainer(obj *appsv1.DaemonSet, config *gpuv1.Clust
 		if strings.HasPrefix(initContainer.Name, "driver") {
 			if len(config.Validator.Driver.Env) > 0 {
 				for _, env := range config.Validator.Driver.Env {
-					setContainerEnv(&(obj.Spec.Template.Spec.InitContainers[i]), env.Name, env.Value)
+					setContainerEnvVar(&(obj.Spec.Template.Spec.InitContainers[i]), env)
 				}
 			}
 		}
@@ -2210,13 +2363,13 @@ func transformValidationInitContainer(obj *appsv1.DaemonSet, config *gpuv1.Clust
 		if strings.HasPrefix(initContainer.Name, "toolkit") {
 			if len(config.Validator.Toolkit.Env) > 0 {
 				for _, env := range config.Validator.Toolkit.Env {
-					setContainerEnv(&(obj.Spec.Template.Spec.InitContainers[i]), env.Name, env.Value)
+					setContainerEnvVar(&(obj.Spec.Template.Spec.InitContainers[i]), env)
 				}
 			}
 		}
 
 		// update validation image
-		image, err := gpuv1.ImagePath(&config.Validator)
+		image, err := gpuv1.ImagePath(&config.Validator, config.Operator.ImageRegistryMirrors)
 		if err != nil {
 			return err
 		}
@@ -2421,21 +2574,10 @@ func Deployment(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	if err := n.rec.Client.Create(ctx, obj); err != nil {
-		if errors.IsAlreadyExists(err) {
-			logger.Info("Found Resource, updating...")
-			err = n.rec.Client.Update(ctx, obj)
-			if err != nil {
-				logger.Info("Couldn't update", "Error", err)
-				return gpuv1.NotReady, err
-			}
-			return isDeploymentReady(obj.Name, n), nil
-		}
-
-		logger.Info("Couldn't create", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
-
 	return isDeploymentReady(obj.Name, n), nil
 }
 
@@ -2944,42 +3086,18 @@ func DaemonSet(n ClusterPolicyController) (gpuv1.State, error) {
 		obj.Annotations[annoKey] = annoValue
 	}
 
-	found := &appsv1.DaemonSet{}
-	err = n.rec.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("DaemonSet not found, creating",
+	// the DaemonSet is applied even if the hash of its spec is unchanged, which corrects
+	// the changes made by others to the fields the operator sets; applying an unchanged
+	// spec does not roll the pods out
+	obj.Annotations[NvidiaAnnotationHashKey] = getDaemonsetHash(obj)
+	err = serverSideApply(n, obj)
+	if err != nil {
+		logger.Info("Couldn't apply DaemonSet",
 			"Name", obj.Name,
+			"Error", err,
 		)
-		// generate hash for the spec to create
-		hashStr := getDaemonsetHash(obj)
-		// add annotation to the Daemonset with hash value during creation
-		obj.Annotations[NvidiaAnnotationHashKey] = hashStr
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create DaemonSet",
-				"Name", obj.Name,
-				"Error", err,
-			)
-			return gpuv1.NotReady, err
-		}
-		return isDaemonSetReady(obj.Name, n), nil
-	} else if err != nil {
-		logger.Info("Failed to get DaemonSet from client",
-			"Name", obj.Name,
-			"Error", err.Error())
 		return gpuv1.NotReady, err
 	}
-
-	changed := isDaemonsetSpecChanged(found, obj)
-	if changed {
-		logger.Info("DaemonSet is different, updating", "name", obj.ObjectMeta.Name)
-		err = n.rec.Client.Update(ctx, obj)
-		if err != nil {
-			return gpuv1.NotReady, err
-		}
-	} else {
-		logger.Info("DaemonSet identical, skipping update", "name", obj.ObjectMeta.Name)
-	}
 	return isDaemonSetReady(obj.Name, n), nil
 }
 
@@ -2991,39 +3109,6 @@ func getDaemonsetHash(daemonset *appsv1.DaemonSet) string {
 	return strconv.FormatUint(hash, 16)
 }
 
-// isDaemonsetSpecChanged returns true if the spec has changed between existing one
-// and new Daemonset spec compared by hash.
-func isDaemonsetSpecChanged(current *appsv1.DaemonSet, new *appsv1.DaemonSet) bool {
-	if current == nil && new != nil {
-		return true
-	}
-	if current.Annotations == nil || new.Annotations == nil {
-		panic("appsv1.DaemonSet.Annotations must be allocated prior to calling isDaemonsetSpecChanged()")
-	}
-
-	hashStr := getDaemonsetHash(new)
-	foundHashAnnotation := false
-
-	for annotation, value := range current.Annotations {
-		if annotation == NvidiaAnnotationHashKey {
-			if value != hashStr {
-				// update annotation to be added to Daemonset as per new spec and indicate spec update is required
-				new.Annotations[NvidiaAnnotationHashKey] = hashStr
-				return true
-			}
-			foundHashAnnotation = true
-			break
-		}
-	}
-
-	if !foundHashAnnotation {
-		// update annotation to be added to Daemonset as per new spec and indicate spec update is required
-		new.Annotations[NvidiaAnnotationHashKey] = hashStr
-		return true
-	}
-	return false
-}
-
 // The operator starts two pods in different stages to validate
 // the correct working of the DaemonSets (driver and dp). Therefore
 // the operator waits until the Pod completes and checks the error status
@@ -3083,26 +3168,8 @@ func SecurityContextConstraints(n ClusterPolicyController) (gpuv1.State, error)
 		return gpuv1.NotReady, err
 	}
 
-	found := &secv1.SecurityContextConstraints{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: "", Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
@@ -3131,26 +3198,8 @@ func PodSecurityPolicy(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	found := &policyv1beta1.PodSecurityPolicy{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: "", Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
@@ -3180,27 +3229,8 @@ func Service(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	found := &corev1.Service{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-	obj.Spec.ClusterIP = found.Spec.ClusterIP
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
@@ -3266,33 +3296,14 @@ func ServiceMonitor(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	found := &promv1.ServiceMonitor{}
-	err = n.rec.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
 }
 
 func transformRuntimeClassLegacy(n ClusterPolicyController, spec nodev1.RuntimeClass) (gpuv1.State, error) {
-	ctx := n.ctx
 	obj := &nodev1beta1.RuntimeClass{}
 
 	obj.Name = spec.Name
@@ -3313,33 +3324,14 @@ func transformRuntimeClassLegacy(n ClusterPolicyController, spec nodev1.RuntimeC
 		return gpuv1.NotReady, err
 	}
 
-	found := &nodev1beta1.RuntimeClass{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: "", Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
 }
 
 func transformRuntimeClass(n ClusterPolicyController, spec nodev1.RuntimeClass) (gpuv1.State, error) {
-	ctx := n.ctx
 	obj := &nodev1.RuntimeClass{}
 
 	obj.Name = spec.Name
@@ -3360,26 +3352,8 @@ func transformRuntimeClass(n ClusterPolicyController, spec nodev1.RuntimeClass)
 		return gpuv1.NotReady, err
 	}
 
-	found := &nodev1.RuntimeClass{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: "", Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
@@ -3419,7 +3393,6 @@ func RuntimeClasses(n ClusterPolicyController) (gpuv1.State, error) {
 
 // PrometheusRule creates PrometheusRule object
 func PrometheusRule(n ClusterPolicyController) (gpuv1.State, error) {
-	ctx := n.ctx
 	state := n.idx
 	obj := n.resources[state].PrometheusRule.DeepCopy()
 	obj.Namespace = n.operatorNamespace
@@ -3430,26 +3403,8 @@ func PrometheusRule(n ClusterPolicyController) (gpuv1.State, error) {
 		return gpuv1.NotReady, err
 	}
 
-	found := &promv1.PrometheusRule{}
-	err := n.rec.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, found)
-	if err != nil && errors.IsNotFound(err) {
-		logger.Info("Not found, creating...")
-		err = n.rec.Client.Create(ctx, obj)
-		if err != nil {
-			logger.Info("Couldn't create", "Error", err)
-			return gpuv1.NotReady, err
-		}
-		return gpuv1.Ready, nil
-	} else if err != nil {
-		return gpuv1.NotReady, err
-	}
-
-	logger.Info("Found Resource, updating...")
-	obj.ResourceVersion = found.ResourceVersion
-
-	err = n.rec.Client.Update(ctx, obj)
-	if err != nil {
-		logger.Info("Couldn't update", "Error", err)
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
 		return gpuv1.NotReady, err
 	}
 	return gpuv1.Ready, nil
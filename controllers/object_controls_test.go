@@ -16,10 +16,6 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	nodev1 "k8s.io/api/node/v1"
-	policyv1beta1 "k8s.io/api/policy/v1beta1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	schedv1 "k8s.io/api/scheduling/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -49,12 +46,10 @@ type testConfig struct {
 }
 
 var (
-	cfg                     *testConfig
-	clusterPolicyController ClusterPolicyController
-	clusterPolicyReconciler ClusterPolicyReconciler
-	clusterPolicy           gpuv1.ClusterPolicy
-	boolTrue                *bool
-	boolFalse               *bool
+	cfg           *testConfig
+	clusterPolicy gpuv1.ClusterPolicy
+	boolTrue      *bool
+	boolFalse     *bool
 )
 
 var nfdLabels = map[string]string{
@@ -64,27 +59,6 @@ var nfdLabels = map[string]string{
 	nfdOSVersionIDLabelKey: "22.04",
 }
 
-var kubernetesResources = []client.Object{
-	&corev1.ServiceAccount{},
-	&rbacv1.Role{},
-	&rbacv1.RoleBinding{},
-	&rbacv1.ClusterRole{},
-	&rbacv1.ClusterRoleBinding{},
-	&corev1.ConfigMap{},
-	&appsv1.DaemonSet{},
-	&appsv1.Deployment{},
-	&corev1.Pod{},
-	&corev1.Service{},
-	&promv1.ServiceMonitor{},
-	&schedv1.PriorityClass{},
-	//&corev1.Taint{},
-	&secv1.SecurityContextConstraints{},
-	&policyv1beta1.PodSecurityPolicy{},
-	&corev1.Namespace{},
-	&nodev1.RuntimeClass{},
-	&promv1.PrometheusRule{},
-}
-
 type commonDaemonsetSpec struct {
 	repository       string
 	image            string
@@ -128,13 +102,10 @@ func getModuleRoot(dir string) (string, error) {
 	return dir, nil
 }
 
-// setup creates a mock kubernetes cluster and client. Nodes are labeled with the minimum
-// required NFD labels to be detected as GPU nodes by the GPU Operator. A sample
-// ClusterPolicy resource is applied to the cluster. The ClusterPolicyController
-// object is initialized with the mock kubernetes client as well as other steps
-// mimicking init() in state_manager.go
+// setup registers the types used by the GPU Operator to the scheme and reads
+// the sample ClusterPolicy. Every test creates its own mock cluster and
+// ClusterPolicyController from them with newTestController
 func setup() error {
-	ctx := context.Background()
 	// Used when updating ClusterPolicy spec
 	boolFalse = new(bool)
 	boolTrue = new(bool)
@@ -154,13 +125,11 @@ func setup() error {
 		return fmt.Errorf("unable to add secv1 schema: %v", err)
 	}
 
-	client, err := newCluster(cfg.nodes, s)
+	// Get a sample ClusterPolicy manifest
+	manifests, err := getAssetsFrom(os.DirFS(cfg.root), clusterPolicyPath)
 	if err != nil {
-		return fmt.Errorf("unable to create cluster: %v", err)
+		return fmt.Errorf("failed to read sample ClusterPolicy manifest: %v", err)
 	}
-
-	// Get a sample ClusterPolicy manifest
-	manifests := getAssetsFrom(&clusterPolicyController, filepath.Join(cfg.root, clusterPolicyPath))
 	clusterPolicyManifest := manifests[0]
 	ser := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme, scheme.Scheme,
 		json.SerializerOptions{Yaml: true, Pretty: false, Strict: false})
@@ -169,70 +138,72 @@ func setup() error {
 		return fmt.Errorf("failed to decode sample ClusterPolicy manifest: %v", err)
 	}
 
-	err = client.Create(ctx, &clusterPolicy)
-	if err != nil {
-		return fmt.Errorf("failed to create ClusterPolicy resource: %v", err)
-	}
-
-	// Confirm ClusterPolicy is deployed in mock cluster
-	cp := &gpuv1.ClusterPolicy{}
-	err = client.Get(ctx, types.NamespacedName{Namespace: "", Name: clusterPolicyName}, cp)
-	if err != nil {
-		return fmt.Errorf("unable to get ClusterPolicy from client: %v", err)
-	}
-
 	opts := zap.Options{
 		Development: true,
 	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	return nil
+}
 
-	clusterPolicyReconciler = ClusterPolicyReconciler{
-		Client: client,
-		Log:    ctrl.Log.WithName("controller").WithName("ClusterPolicy"),
-		Scheme: s,
-	}
+// newTestController creates a mock kubernetes cluster and client. Nodes are labeled with
+// the minimum required NFD labels to be detected as GPU nodes by the GPU Operator. The
+// sample ClusterPolicy resource is applied to the cluster. The returned
+// ClusterPolicyController is initialized with the mock kubernetes client as well as
+// other steps mimicking init() in state_manager.go
+func newTestController(t *testing.T) *ClusterPolicyController {
+	ctx := context.Background()
 
-	clusterPolicyController = ClusterPolicyController{
-		ctx:       ctx,
-		singleton: cp,
-		rec:       &clusterPolicyReconciler,
-	}
+	client, err := newCluster(cfg.nodes, scheme.Scheme)
+	require.NoError(t, err, "unable to create cluster")
 
-	clusterPolicyController.operatorMetrics = initOperatorMetrics(&clusterPolicyController)
+	err = client.Create(ctx, clusterPolicy.DeepCopy())
+	require.NoError(t, err, "failed to create ClusterPolicy resource")
 
-	hasNFDLabels, gpuNodeCount, err := clusterPolicyController.labelGPUNodes()
-	if err != nil {
-		return fmt.Errorf("unable to label nodes in cluster: %v", err)
-	}
-	if gpuNodeCount == 0 {
-		return fmt.Errorf("no gpu nodes in mock cluster")
+	// Confirm ClusterPolicy is deployed in mock cluster
+	cp := &gpuv1.ClusterPolicy{}
+	err = client.Get(ctx, types.NamespacedName{Namespace: "", Name: clusterPolicyName}, cp)
+	require.NoError(t, err, "unable to get ClusterPolicy from client")
+
+	n := &ClusterPolicyController{
+		ctx:       ctx,
+		singleton: cp,
+		rec: &ClusterPolicyReconciler{
+			Client: client,
+			Log:    ctrl.Log.WithName("controller").WithName("ClusterPolicy"),
+			Scheme: scheme.Scheme,
+		},
+		operatorMetrics: newOperatorMetrics(),
 	}
 
-	clusterPolicyController.hasGPUNodes = gpuNodeCount != 0
-	clusterPolicyController.hasNFDLabels = hasNFDLabels
+	hasNFDLabels, gpuNodeCount, err := n.labelGPUNodes()
+	require.NoError(t, err, "unable to label nodes in cluster")
+	require.NotZero(t, gpuNodeCount, "no gpu nodes in mock cluster")
+	n.hasGPUNodes = gpuNodeCount != 0
+	n.hasNFDLabels = hasNFDLabels
 
 	// setup kernelVersionMap for pre-compiled driver tests
-	kernelVersionMap, err := clusterPolicyController.getKernelVersionsMap()
-	if err != nil {
-		return fmt.Errorf("Unable to obtain all kernel versions of the GPU nodes in the cluster: %v", err)
-	}
-	clusterPolicyController.kernelVersionMap = kernelVersionMap
-	return nil
+	n.kernelVersionMap, err = n.getKernelVersionsMap()
+	require.NoError(t, err, "unable to obtain all kernel versions of the GPU nodes in the cluster")
+	return n
 }
 
 // newCluster creates a mock kubernetes cluster and returns the corresponding client object
 func newCluster(nodes int, s *runtime.Scheme) (client.Client, error) {
 	ctx := context.Background()
 	// Build fake client
-	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	cl := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&gpuv1.ClusterPolicy{}).Build()
 
 	for i := 0; i < nodes; i++ {
 		ready := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}
 		name := fmt.Sprintf("node%d", i)
+		labels := map[string]string{}
+		for key, value := range nfdLabels {
+			labels[key] = value
+		}
 		n := &corev1.Node{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   name,
-				Labels: nfdLabels,
+				Labels: labels,
 			},
 			Status: corev1.NodeStatus{
 				Conditions: []corev1.NodeCondition{
@@ -250,7 +221,7 @@ func newCluster(nodes int, s *runtime.Scheme) (client.Client, error) {
 		}
 	}
 
-	return cl, nil
+	return &applyClient{Client: cl}, nil
 }
 
 // updateClusterPolicy updates an existing ClusterPolicy instance
@@ -263,24 +234,6 @@ func updateClusterPolicy(n *ClusterPolicyController, cp *gpuv1.ClusterPolicy) er
 	return nil
 }
 
-// removeState deletes all resources, controls, and stateNames tracked
-// by ClusterPolicyController at a specific index. It also deletes
-// all objects from the mock k8s client
-func removeState(n *ClusterPolicyController, idx int) error {
-	var err error
-	for _, res := range kubernetesResources {
-		// TODO: use n.operatorNamespace once MR is merged
-		err = n.rec.Client.DeleteAllOf(n.ctx, res)
-		if err != nil {
-			return fmt.Errorf("error deleting objects from k8s client: %v", err)
-		}
-	}
-	n.resources = append(n.resources[:idx], n.resources[idx+1:]...)
-	n.controls = append(n.controls[:idx], n.controls[idx+1:]...)
-	n.stateNames = append(n.stateNames[:idx], n.stateNames[idx+1:]...)
-	return nil
-}
-
 // getImagePullSecrets converts a slice of strings (pull secrets)
 // to the corev1 type used by k8s
 func getImagePullSecrets(secrets []string) []corev1.LocalObjectReference {
@@ -294,7 +247,7 @@ func getImagePullSecrets(secrets []string) []corev1.LocalObjectReference {
 // testDaemonsetCommon executes one test case for a particular Daemonset,
 // and checks the values for common fields used throughout all Daemonsets
 // managed by the GPU Operator.
-func testDaemonsetCommon(t *testing.T, cp *gpuv1.ClusterPolicy, component string, numDaemonsets int) (*appsv1.DaemonSet, error) {
+func testDaemonsetCommon(t *testing.T, n *ClusterPolicyController, cp *gpuv1.ClusterPolicy, component string, numDaemonsets int) (*appsv1.DaemonSet, error) {
 	ctx := context.Background()
 
 	var spec commonDaemonsetSpec
@@ -317,8 +270,8 @@ func testDaemonsetCommon(t *testing.T, cp *gpuv1.ClusterPolicy, component string
 		}
 		dsLabel = "nvidia-driver-daemonset"
 		mainCtrName = "nvidia-driver"
-		manifestFile = filepath.Join(cfg.root, driverAssetsPath)
-		mainCtrImage, err = resolveDriverTag(clusterPolicyController, &cp.Spec.Driver)
+		manifestFile = driverAssetsPath
+		mainCtrImage, err = resolveDriverTag(*n, &cp.Spec.Driver)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get mainCtrImage for driver: %v", err)
 		}
@@ -333,10 +286,10 @@ func testDaemonsetCommon(t *testing.T, cp *gpuv1.ClusterPolicy, component string
 			env:              cp.Spec.DevicePlugin.Env,
 			resources:        cp.Spec.DevicePlugin.Resources,
 		}
-		dsLabel = "nvidia-device-plugin-daemonset"
-		mainCtrName = "nvidia-device-plugin"
-		manifestFile = filepath.Join(cfg.root, devicePluginAssetsPath)
-		mainCtrImage, err = gpuv1.ImagePath(&cp.Spec.DevicePlugin)
+		dsLabel = "xdxct-device-plugin-daemonset"
+		mainCtrName = "xdxct-device-plugin"
+		manifestFile = devicePluginAssetsPath
+		mainCtrImage, err = gpuv1.ImagePath(&cp.Spec.DevicePlugin, cp.Spec.Operator.ImageRegistryMirrors)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get mainCtrImage for device-plugin: %v", err)
 		}
@@ -345,18 +298,18 @@ func testDaemonsetCommon(t *testing.T, cp *gpuv1.ClusterPolicy, component string
 	}
 
 	// update cluster policy
-	err = updateClusterPolicy(&clusterPolicyController, cp)
+	err = updateClusterPolicy(n, cp)
 	if err != nil {
 		t.Fatalf("error in test setup: %v", err)
 	}
 
 	// add manifests
-	err = addState(&clusterPolicyController, manifestFile)
+	err = addState(n, os.DirFS(cfg.root), manifestFile)
 	if err != nil {
 		t.Fatalf("unable to add state: %v", err)
 	}
 	// create resources
-	_, err = clusterPolicyController.step()
+	_, err = n.step()
 	if err != nil {
 		t.Errorf("error creating resources: %v", err)
 	}
@@ -365,7 +318,7 @@ func testDaemonsetCommon(t *testing.T, cp *gpuv1.ClusterPolicy, component string
 		client.MatchingLabels{"app": dsLabel},
 	}
 	list := &appsv1.DaemonSetList{}
-	err = clusterPolicyController.rec.Client.List(ctx, list, opts...)
+	err = n.rec.Client.List(ctx, list, opts...)
 	if err != nil {
 		t.Fatalf("could not get DaemonSetList from client: %v", err)
 	}
@@ -490,7 +443,7 @@ func TestDriver(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			ds, err := testDaemonsetCommon(t, tc.clusterPolicy, "Driver", tc.output["numDaemonsets"].(int))
+			ds, err := testDaemonsetCommon(t, newTestController(t), tc.clusterPolicy, "Driver", tc.output["numDaemonsets"].(int))
 			if err != nil {
 				t.Fatalf("error in testDaemonsetCommon(): %v", err)
 			}
@@ -524,13 +477,6 @@ func TestDriver(t *testing.T) {
 			require.Equal(t, tc.output["nvPeerMemPresent"], nvPeerMemPresent, "Unexpected configuration for nv-peermem container")
 			require.Equal(t, tc.output["driverImage"], driverImage, "Unexpected configuration for nvidia-driver-ctr image")
 			require.Equal(t, tc.output["driverManagerImage"], driverManagerImage, "Unexpected configuration for k8s-driver-manager image")
-
-			// cleanup by deleting all kubernetes objects
-			err = removeState(&clusterPolicyController, clusterPolicyController.idx-1)
-			if err != nil {
-				t.Fatalf("error removing state %v:", err)
-			}
-			clusterPolicyController.idx--
 		})
 	}
 }
@@ -577,9 +523,8 @@ func getDevicePluginTestOutput(testCase string) map[string]interface{} {
 	case "default":
 		output["env"] = map[string]string{}
 	case "custom-config":
-		// Ensure config-manager containers are added
-		output["configManagerInitPresent"] = true
-		output["configManagerSidecarPresent"] = true
+		// the xdxct-device-plugin asset ships no config-manager containers,
+		// only the plugin itself is pointed at the config
 		output["env"] = map[string]string{
 			"CONFIG_FILE": "/config/config.yaml",
 		}
@@ -612,7 +557,7 @@ func TestDevicePlugin(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			ds, err := testDaemonsetCommon(t, tc.clusterPolicy, "DevicePlugin", tc.output["numDaemonsets"].(int))
+			ds, err := testDaemonsetCommon(t, newTestController(t), tc.clusterPolicy, "DevicePlugin", tc.output["numDaemonsets"].(int))
 			if err != nil {
 				t.Fatalf("error in testDaemonsetCommon(): %v", err)
 			}
@@ -630,7 +575,7 @@ func TestDevicePlugin(t *testing.T) {
 				}
 			}
 			for i, container := range ds.Spec.Template.Spec.Containers {
-				if container.Name == "nvidia-device-plugin" {
+				if container.Name == "xdxct-device-plugin" {
 					devicePluginImage = container.Image
 					mainCtrIdx = i
 					continue
@@ -642,7 +587,7 @@ func TestDevicePlugin(t *testing.T) {
 
 			require.Equal(t, tc.output["configManagerInitPresent"], configManagerInitPresent, "Unexpected configuration for config-manager init container")
 			require.Equal(t, tc.output["configManagerSidecarPresent"], configManagerSidecarPresent, "Unexpected configuration for config-manager sidecar container")
-			require.Equal(t, tc.output["devicePluginImage"], devicePluginImage, "Unexpected configuration for nvidia-device-plugin image")
+			require.Equal(t, tc.output["devicePluginImage"], devicePluginImage, "Unexpected configuration for xdxct-device-plugin image")
 
 			for key, value := range tc.output["env"].(map[string]string) {
 				envFound := false
@@ -652,16 +597,9 @@ func TestDevicePlugin(t *testing.T) {
 					}
 				}
 				if !envFound {
-					t.Fatalf("Expected env is not set for daemonset nvidia-device-plugin %s->%s", key, value)
+					t.Fatalf("Expected env is not set for daemonset xdxct-device-plugin %s->%s", key, value)
 				}
 			}
-
-			// cleanup by deleting all kubernetes objects
-			err = removeState(&clusterPolicyController, clusterPolicyController.idx-1)
-			if err != nil {
-				t.Fatalf("error removing state %v:", err)
-			}
-			clusterPolicyController.idx--
 		})
 	}
 }
@@ -687,14 +625,15 @@ func getVGPUManagerTestOutput(testCase string) map[string]interface{} {
 }
 
 func TestVGPUManagerAssets(t *testing.T) {
-	manifestPath := filepath.Join(cfg.root, vGPUManagerAssetsPath)
+	t.Skip("the state-vgpu-manager assets are not shipped")
+	n := newTestController(t)
 	// add manifests
-	err := addState(&clusterPolicyController, manifestPath)
+	err := addState(n, os.DirFS(cfg.root), vGPUManagerAssetsPath)
 	if err != nil {
 		t.Fatalf("unable to add state: %v", err)
 	}
 	// create resources
-	_, err = clusterPolicyController.step()
+	_, err = n.step()
 	if err != nil {
 		t.Errorf("error creating resources: %v", err)
 	}
@@ -718,3 +657,344 @@ func getSandboxDevicePluginTestOutput(testCase string) map[string]interface{} {
 
 	return output
 }
+
+func TestApplyComponentSchedulingConfig(t *testing.T) {
+	commonToleration := corev1.Toleration{Key: "xdxct.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	componentToleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu-pool", Operator: corev1.NodeSelectorOpExists}},
+				}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		name        string
+		daemonsets  gpuv1.DaemonsetsSpec
+		sched       gpuv1.SchedulingSpec
+		verify      func(t *testing.T, ds *appsv1.DaemonSet)
+	}{
+		{
+			"no component overrides keeps common config",
+			"xdxct-device-plugin-daemonset",
+			gpuv1.DaemonsetsSpec{PriorityClassName: "common", Tolerations: []corev1.Toleration{commonToleration}},
+			gpuv1.SchedulingSpec{},
+			func(t *testing.T, ds *appsv1.DaemonSet) {
+				require.Equal(t, "common", ds.Spec.Template.Spec.PriorityClassName)
+				require.Equal(t, []corev1.Toleration{commonToleration}, ds.Spec.Template.Spec.Tolerations)
+				require.Equal(t, map[string]string{"xdxct.com/gpu.deploy.device-plugin": "true"}, ds.Spec.Template.Spec.NodeSelector)
+			},
+		},
+		{
+			"component overrides merge on top of common config",
+			"xdxct-device-plugin-daemonset",
+			gpuv1.DaemonsetsSpec{PriorityClassName: "common", Tolerations: []corev1.Toleration{commonToleration}},
+			gpuv1.SchedulingSpec{
+				PriorityClassName: "component",
+				Tolerations:       []corev1.Toleration{commonToleration, componentToleration},
+				NodeSelector:      map[string]string{"gpu-pool": "a"},
+				Affinity:          affinity,
+			},
+			func(t *testing.T, ds *appsv1.DaemonSet) {
+				require.Equal(t, "component", ds.Spec.Template.Spec.PriorityClassName)
+				require.Equal(t, []corev1.Toleration{commonToleration, componentToleration}, ds.Spec.Template.Spec.Tolerations)
+				require.Equal(t, map[string]string{"xdxct.com/gpu.deploy.device-plugin": "true", "gpu-pool": "a"}, ds.Spec.Template.Spec.NodeSelector)
+				require.Equal(t, affinity, ds.Spec.Template.Spec.Affinity)
+			},
+		},
+		{
+			"component rolling update overrides common OnDelete",
+			"xdxct-device-plugin-daemonset",
+			gpuv1.DaemonsetsSpec{UpdateStrategy: "OnDelete"},
+			gpuv1.SchedulingSpec{UpdateStrategy: "RollingUpdate", RollingUpdate: &gpuv1.RollingUpdateSpec{MaxUnavailable: "25%"}},
+			func(t *testing.T, ds *appsv1.DaemonSet) {
+				require.Equal(t, appsv1.RollingUpdateDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+				require.NotNil(t, ds.Spec.UpdateStrategy.RollingUpdate)
+				require.Equal(t, "25%", ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable.String())
+			},
+		},
+		{
+			"component OnDelete overrides common rolling update",
+			"xdxct-device-plugin-daemonset",
+			gpuv1.DaemonsetsSpec{RollingUpdate: &gpuv1.RollingUpdateSpec{MaxUnavailable: "1"}},
+			gpuv1.SchedulingSpec{UpdateStrategy: "OnDelete"},
+			func(t *testing.T, ds *appsv1.DaemonSet) {
+				require.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+				require.Nil(t, ds.Spec.UpdateStrategy.RollingUpdate)
+			},
+		},
+		{
+			"rolling update is not allowed for the driver",
+			"nvidia-driver-daemonset",
+			gpuv1.DaemonsetsSpec{},
+			gpuv1.SchedulingSpec{UpdateStrategy: "RollingUpdate", RollingUpdate: &gpuv1.RollingUpdateSpec{MaxUnavailable: "1"}},
+			func(t *testing.T, ds *appsv1.DaemonSet) {
+				require.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.name},
+				Spec: appsv1.DaemonSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							NodeSelector: map[string]string{"xdxct.com/gpu.deploy.device-plugin": "true"},
+						},
+					},
+				},
+			}
+			if tc.name == "nvidia-driver-daemonset" {
+				ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+			}
+			config := &gpuv1.ClusterPolicySpec{Daemonsets: tc.daemonsets}
+			config.DevicePlugin.SchedulingSpec = tc.sched
+			config.Driver.SchedulingSpec = tc.sched
+
+			err := applyCommonDaemonsetConfig(ds, config)
+			require.NoError(t, err)
+			err = applyComponentSchedulingConfig(ds, config, getComponentSchedulingSpec(ds.Name, config))
+			require.NoError(t, err)
+			tc.verify(t, ds)
+		})
+	}
+}
+
+func TestSetContainerEnvVar(t *testing.T) {
+	nodeName := &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}
+	token := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "licensing"},
+		Key:                  "token",
+	}}
+
+	c := &corev1.Container{Env: []corev1.EnvVar{{Name: "NODE_NAME", Value: "placeholder"}, {Name: "TOKEN", ValueFrom: token}}}
+
+	// valueFrom replaces a literal value from the asset
+	setContainerEnvVar(c, gpuv1.EnvVar{Name: "NODE_NAME", ValueFrom: nodeName})
+	// literal value replaces a valueFrom from the asset
+	setContainerEnvVar(c, gpuv1.EnvVar{Name: "TOKEN", Value: "plain"})
+	// new variables are appended
+	setContainerEnvVar(c, gpuv1.EnvVar{Name: "DEBUG", Value: "true"})
+
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "NODE_NAME", ValueFrom: nodeName},
+		{Name: "TOKEN", Value: "plain"},
+		{Name: "DEBUG", Value: "true"},
+	}, c.Env)
+}
+
+func TestConfigManagerEnv(t *testing.T) {
+	token := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "licensing"},
+		Key:                  "token",
+	}}
+	managerEnv := []corev1.EnvVar{
+		{Name: "ONESHOT", Value: "false"},
+		{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		{Name: "CONFIG_FILE_SRCDIR", Value: "/available-configs"},
+		{Name: "DEFAULT_CONFIG", Value: ""},
+	}
+
+	config := &gpuv1.ClusterPolicySpec{}
+	config.DevicePlugin.Repository = "nvcr.io/nvidia"
+	config.DevicePlugin.Image = "k8s-device-plugin"
+	config.DevicePlugin.Version = "v0.14.1"
+	config.DevicePlugin.Config = &gpuv1.DevicePluginConfig{Name: "plugin-config", Default: "default"}
+	config.DevicePlugin.Env = []gpuv1.EnvVar{
+		{Name: "ONESHOT", Value: "true"},
+		{Name: "CONFIG_FILE_SRCDIR", ValueFrom: token},
+		{Name: "DEFAULT_CONFIG", ValueFrom: token},
+		{Name: "FALLBACK_STRATEGIES", Value: "named"},
+		{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+		{Name: "TOKEN", ValueFrom: token},
+	}
+
+	ds := &appsv1.DaemonSet{}
+	ds.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "config-manager-init", Env: append([]corev1.EnvVar{}, managerEnv...)}}
+	ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: "config-manager", Env: append([]corev1.EnvVar{}, managerEnv...)}}
+
+	require.NoError(t, transformConfigManagerInitContainer(ds, config))
+	require.NoError(t, transformConfigManagerSidecarContainer(ds, config))
+
+	// the env of the config-manager is kept, the other env of the device-plugin is appended
+	expected := []corev1.EnvVar{
+		{Name: "ONESHOT", Value: "false"},
+		{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		{Name: "CONFIG_FILE_SRCDIR", Value: "/available-configs"},
+		{Name: "DEFAULT_CONFIG", Value: "default"},
+		{Name: "FALLBACK_STRATEGIES", Value: "empty"},
+		{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+		{Name: "TOKEN", ValueFrom: token},
+	}
+	require.Equal(t, expected, ds.Spec.Template.Spec.InitContainers[0].Env)
+	require.Equal(t, expected, ds.Spec.Template.Spec.Containers[0].Env)
+}
+
+func TestMirrorImage(t *testing.T) {
+	mirrors := []gpuv1.ImageRegistryMirror{
+		{Source: "nvcr.io", Mirror: "registry.example.com/nvcr.io"},
+		{Source: "nvcr.io/nvidia/k8s/", Mirror: "registry.example.com/k8s/"},
+		{Source: "docker.io/xdxct", Mirror: "registry.example.com/xdxct"},
+	}
+
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{"nvcr.io/nvidia/cuda:12.1.0-base-ubi8", "registry.example.com/nvcr.io/nvidia/cuda:12.1.0-base-ubi8"},
+		{"nvcr.io/nvidia/k8s/container-toolkit:v1.13.0", "registry.example.com/k8s/container-toolkit:v1.13.0"},
+		{"docker.io/xdxct/device-plugin@sha256:abcd", "registry.example.com/xdxct/device-plugin@sha256:abcd"},
+		{"docker.io/xdxct-test/device-plugin:v1", "docker.io/xdxct-test/device-plugin:v1"},
+		{"quay.io/xdxct/driver:1.0", "quay.io/xdxct/driver:1.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			require.Equal(t, tc.expected, gpuv1.MirrorImage(tc.image, mirrors))
+		})
+	}
+}
+
+func TestValidatorImageRegistryMirrors(t *testing.T) {
+	config := &gpuv1.ClusterPolicySpec{}
+	config.Validator.Repository = "nvcr.io/nvidia/cloud-native"
+	config.Validator.Image = "gpu-operator-validator"
+	config.Validator.Version = "v23.3.2"
+	config.Operator.ImageRegistryMirrors = []gpuv1.ImageRegistryMirror{{Source: "nvcr.io", Mirror: "registry.example.com"}}
+
+	podSpec := &corev1.PodSpec{InitContainers: []corev1.Container{{Name: "plugin-validation"}}}
+	err := TransformValidatorComponent(config, podSpec, "plugin")
+	require.NoError(t, err)
+
+	expected := "registry.example.com/nvidia/cloud-native/gpu-operator-validator:v23.3.2"
+	require.Equal(t, expected, podSpec.InitContainers[0].Image)
+	// the plugin validation workload pod is spun off with the mirrored image
+	require.Equal(t, expected, getContainerEnv(&podSpec.InitContainers[0], ValidatorImageEnvName))
+}
+
+// patchRecorder records the patches sent through the client
+type patchRecorder struct {
+	client.Client
+	patchType types.PatchType
+	data      []byte
+	options   client.PatchOptions
+}
+
+func (r *patchRecorder) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	r.patchType = patch.Type()
+	r.data = data
+	r.options = client.PatchOptions{}
+	r.options.ApplyOptions(opts)
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestServerSideApply(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewRenderScheme()
+	require.NoError(t, err)
+	c := &patchRecorder{Client: &applyClient{Client: fake.NewClientBuilder().WithScheme(s).Build()}}
+	n := ClusterPolicyController{
+		ctx: ctx,
+		rec: &ClusterPolicyReconciler{Client: c, Scheme: s},
+	}
+
+	labels := map[string]string{"app": "xdxct-device-plugin-daemonset"}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "xdxct-device-plugin-daemonset", Namespace: "gpu-operator"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nvidia-device-plugin", Image: "device-plugin:v1"}}},
+			},
+		},
+	}
+	require.NoError(t, serverSideApply(n, ds.DeepCopy()))
+
+	require.Equal(t, types.ApplyPatchType, c.patchType)
+	require.Equal(t, "xdxct-gpu-operator", c.options.FieldManager)
+	require.NotNil(t, c.options.Force)
+	require.True(t, *c.options.Force)
+	applied := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(c.data, &applied))
+	require.Equal(t, "apps/v1", applied["apiVersion"])
+	require.Equal(t, "DaemonSet", applied["kind"])
+	// only the fields set by the operator are applied, the fake client handles apply
+	// patches as strategic merge patches so the ownership of the fields the API server
+	// derives from them cannot be checked here
+	require.NotContains(t, applied, "status")
+	require.NotContains(t, applied["metadata"], "creationTimestamp")
+	require.NotContains(t, applied["metadata"], "resourceVersion")
+	require.NotContains(t, applied["metadata"], "annotations")
+}
+
+func TestServerSideApplyUpgradesManagedFields(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewRenderScheme()
+	require.NoError(t, err)
+	c := &patchRecorder{Client: &applyClient{Client: fake.NewClientBuilder().WithScheme(s).Build()}}
+	n := ClusterPolicyController{
+		ctx: ctx,
+		rec: &ClusterPolicyReconciler{Client: c, Scheme: s},
+	}
+
+	// a ConfigMap created and updated by a release of the operator without server-side apply
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "plugin-config",
+			Namespace: "gpu-operator",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:    "gpu-operator",
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: "v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{".":{},"f:removed-key":{},"f:key":{}}}`)},
+				},
+				{
+					Manager:    "kubectl-edit",
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: "v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:team":{}}}}`)},
+				},
+			},
+		},
+		Data: map[string]string{"key": "value", "removed-key": "value"},
+	}
+	require.NoError(t, c.Create(ctx, cm.DeepCopy()))
+
+	obj := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin-config", Namespace: "gpu-operator"},
+		Data:       map[string]string{"key": "value"},
+	}
+	require.NoError(t, serverSideApply(n, obj))
+
+	found := &corev1.ConfigMap{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(cm), found))
+	managers := map[string]metav1.ManagedFieldsOperationType{}
+	for _, entry := range found.ManagedFields {
+		managers[entry.Manager] = entry.Operation
+	}
+	// the fields of the old manager now belong to the operator, so that the API server
+	// removes removed-key, the fields of the other managers are left untouched
+	require.Equal(t, map[string]metav1.ManagedFieldsOperationType{
+		FieldManager:   metav1.ManagedFieldsOperationApply,
+		"kubectl-edit": metav1.ManagedFieldsOperationUpdate,
+	}, managers)
+
+	// the managed fields are only upgraded once
+	c.patchType = ""
+	require.NoError(t, upgradeManagedFields(n, obj))
+	require.Empty(t, c.patchType)
+}
@@ -19,6 +19,8 @@ type OperatorMetrics struct {
 	reconciliationFailed       promcli.Counter
 	reconciliationHasNFDLabels promcli.Gauge
 
+	statePaused *promcli.GaugeVec
+
 	openshiftDriverToolkitEnabled          promcli.Gauge
 	openshiftDriverToolkitNfdTooOld        promcli.Gauge
 	openshiftDriverToolkitIsMissing        promcli.Gauge
@@ -45,10 +47,55 @@ const (
 
 	driverAutoUpgradeEnabled  = 1
 	driverAutoUpgradeDisabled = 0
+
+	statePausedTrue  = 1
+	statePausedFalse = 0
 )
 
-func initOperatorMetrics(n *ClusterPolicyController) *OperatorMetrics {
-	m := &OperatorMetrics{
+// NewOperatorMetrics creates the operator metrics and registers them with registerer,
+// the controller-runtime metrics registry if nil
+func NewOperatorMetrics(registerer promcli.Registerer) (*OperatorMetrics, error) {
+	if registerer == nil {
+		registerer = metrics.Registry
+	}
+	m := newOperatorMetrics()
+
+	collectors := []promcli.Collector{
+		m.gpuNodesTotal,
+
+		m.reconciliationLastSuccess,
+		m.reconciliationStatus,
+		m.reconciliationTotal,
+		m.reconciliationFailed,
+		m.reconciliationHasNFDLabels,
+
+		m.statePaused,
+
+		m.openshiftDriverToolkitEnabled,
+		m.openshiftDriverToolkitNfdTooOld,
+		m.openshiftDriverToolkitIsMissing,
+		m.openshiftDriverToolkitRhcosTagsMissing,
+		m.openshiftDriverToolkitIsBroken,
+
+		m.driverAutoUpgradeEnabled,
+		m.upgradesInProgress,
+		m.upgradesDone,
+		m.upgradesAvailable,
+		m.upgradesFailed,
+		m.upgradesPending,
+	}
+	for _, c := range collectors {
+		if err := registerer.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register operator metrics: %v", err)
+		}
+	}
+
+	return m, nil
+}
+
+// newOperatorMetrics creates the operator metrics without registering them
+func newOperatorMetrics() *OperatorMetrics {
+	return &OperatorMetrics{
 		gpuNodesTotal: promcli.NewGauge(
 			promcli.GaugeOpts{
 				Name: "gpu_operator_gpu_nodes_total",
@@ -90,6 +137,14 @@ func initOperatorMetrics(n *ClusterPolicyController) *OperatorMetrics {
 			},
 		),
 
+		statePaused: promcli.NewGaugeVec(
+			promcli.GaugeOpts{
+				Name: "gpu_operator_state_paused",
+				Help: "1 if reconciliation of the state is paused, 0 otherwise",
+			},
+			[]string{"state"},
+		),
+
 		openshiftDriverToolkitEnabled: promcli.NewGauge(
 			promcli.GaugeOpts{
 				Name: "gpu_operator_openshift_driver_toolkit_enabled",
@@ -157,29 +212,4 @@ func initOperatorMetrics(n *ClusterPolicyController) *OperatorMetrics {
 			},
 		),
 	}
-
-	metrics.Registry.MustRegister(
-		m.gpuNodesTotal,
-
-		m.reconciliationLastSuccess,
-		m.reconciliationStatus,
-		m.reconciliationTotal,
-		m.reconciliationFailed,
-		m.reconciliationHasNFDLabels,
-
-		m.openshiftDriverToolkitEnabled,
-		m.openshiftDriverToolkitNfdTooOld,
-		m.openshiftDriverToolkitIsMissing,
-		m.openshiftDriverToolkitRhcosTagsMissing,
-		m.openshiftDriverToolkitIsBroken,
-
-		m.driverAutoUpgradeEnabled,
-		m.upgradesInProgress,
-		m.upgradesDone,
-		m.upgradesAvailable,
-		m.upgradesFailed,
-		m.upgradesPending,
-	)
-
-	return m
 }
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// operandComponent is an operand deployed to GPU nodes by a state
+type operandComponent struct {
+	state       string
+	deployLabel string
+	// specField is the field of the ClusterPolicy spec which enables the component
+	specField string
+}
+
+// operandComponents maps the names of the operands, as used in their
+// xdxct.com/gpu.deploy.<name> node label, to their state
+var operandComponents = map[string]operandComponent{
+	"driver":                {state: "state-driver", deployLabel: "xdxct.com/gpu.deploy.driver", specField: "driver"},
+	"container-toolkit":     {state: "state-container-toolkit", deployLabel: "xdxct.com/gpu.deploy.container-toolkit", specField: "toolkit"},
+	"device-plugin":         {state: "state-device-plugin", deployLabel: "xdxct.com/gpu.deploy.device-plugin", specField: "devicePlugin"},
+	"gpu-feature-discovery": {state: "gpu-feature-discovery", deployLabel: "xdxct.com/gpu.deploy.gpu-feature-discovery", specField: "gfd"},
+	"node-status-exporter":  {state: "state-node-status-exporter", deployLabel: "xdxct.com/gpu.deploy.node-status-exporter", specField: "nodeStatusExporter"},
+	"operator-validator":    {state: "state-operator-validation", deployLabel: "xdxct.com/gpu.deploy.operator-validator", specField: "validator"},
+}
+
+// OperandComponents returns the names of the operands ExplainPlacement accepts
+func OperandComponents() []string {
+	names := make([]string, 0, len(operandComponents))
+	for name := range operandComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OperandDeployLabel returns the node label which schedules the given operand on a node
+func OperandDeployLabel(component string) (string, error) {
+	c, ok := operandComponents[component]
+	if !ok {
+		return "", fmt.Errorf("unknown component %q, must be one of [%s]", component, strings.Join(OperandComponents(), ", "))
+	}
+	return c.deployLabel, nil
+}
+
+// PlacementCheck is a condition which must hold for an operand to run on a node
+type PlacementCheck struct {
+	// Check describes what was checked
+	Check string
+	// Passed is false if the condition blocks the operand from running on the node
+	Passed bool
+	// Reason explains the result of the check in plain language
+	Reason string
+}
+
+// placementChecks records the checks of ExplainPlacement
+type placementChecks []PlacementCheck
+
+func (c *placementChecks) pass(check string, format string, args ...interface{}) {
+	*c = append(*c, PlacementCheck{Check: check, Passed: true, Reason: fmt.Sprintf(format, args...)})
+}
+
+// fail records a blocking check and returns all checks
+func (c *placementChecks) fail(check string, format string, args ...interface{}) []PlacementCheck {
+	*c = append(*c, PlacementCheck{Check: check, Passed: false, Reason: fmt.Sprintf(format, args...)})
+	return *c
+}
+
+// ExplainPlacement checks the conditions for component to run on node, in the order the
+// operator and the scheduler apply them, and stops at the first condition which is not
+// met. The operator checks are whether the state is deployed and enabled in cp and how
+// the node is labelled. The scheduler checks are whether the node matches the node
+// selector, node affinity and tolerations of the DaemonSet of the component, which is
+// nil if it does not exist. states are the states deployed by the operator, as set by
+// its --states flag, DefaultOperandStates() if empty
+func ExplainPlacement(cp *gpuv1.ClusterPolicy, states []string, component string, node *corev1.Node, daemonset *appsv1.DaemonSet) ([]PlacementCheck, error) {
+	c, ok := operandComponents[component]
+	if !ok {
+		return nil, fmt.Errorf("unknown component %q, must be one of [%s]", component, strings.Join(OperandComponents(), ", "))
+	}
+
+	n := ClusterPolicyController{
+		singleton: cp,
+		rec:       &ClusterPolicyReconciler{Log: logr.Discard()},
+	}
+	nodeLabels := node.GetLabels()
+	if nodeLabels == nil {
+		nodeLabels = map[string]string{}
+	}
+
+	checks := placementChecks{}
+
+	if !OperandDeployed(component, states) {
+		return checks.fail("state", "the operator does not deploy %s, %s is not one of the states set by its --states flag", component, c.state), nil
+	}
+	if !n.isStateEnabled(c.state) {
+		return checks.fail("state", "%s is disabled in ClusterPolicy %s, spec.%s.enabled is false", component, cp.Name, c.specField), nil
+	}
+	checks.pass("state", "%s is enabled in ClusterPolicy %s", component, cp.Name)
+
+	if !hasCommonGPULabel(nodeLabels) {
+		if !hasGPULabels(nodeLabels) {
+			return checks.fail("gpu node", "node %s has no GPU detected by NFD, none of the labels %s is set to true",
+				node.Name, strings.Join(sortedLabelKeys(gpuNodeLabels), ", ")), nil
+		}
+		return checks.fail("gpu node", "node %s has a GPU but is not labelled %s=%s, the operator labels it on its next reconciliation",
+			node.Name, commonGPULabelKey, commonGPULabelValue), nil
+	}
+	checks.pass("gpu node", "node %s is labelled %s=%s", node.Name, commonGPULabelKey, commonGPULabelValue)
+
+	if hasOperandsDisabled(nodeLabels) {
+		return checks.fail("operands", "all operands are disabled on node %s by the label %s=false", node.Name, commonOperandsLabelKey), nil
+	}
+	checks.pass("operands", "node %s does not disable operands through %s=false", node.Name, commonOperandsLabelKey)
+
+	workloadConfig, err := getWorkloadConfig(nodeLabels, n.sandboxEnabled)
+	workload := fmt.Sprintf("node %s runs the %s workload", node.Name, workloadConfig)
+	if err != nil {
+		workload = fmt.Sprintf("node %s runs the default %s workload (%v)", node.Name, workloadConfig, err)
+	}
+	if _, ok := gpuStateLabels[workloadConfig][c.deployLabel]; !ok {
+		return checks.fail("workload config", "%s, which does not include %s", workload, component), nil
+	}
+	checks.pass("workload config", "%s, which includes %s", workload, component)
+
+	value, labelled := nodeLabels[c.deployLabel]
+	if !labelled {
+		return checks.fail("deploy label", "node %s is missing the label %s, the operator sets it on its next reconciliation", node.Name, c.deployLabel), nil
+	}
+	if value != "true" {
+		return checks.fail("deploy label", "%s is disabled on node %s by the label %s=%s", component, node.Name, c.deployLabel, value), nil
+	}
+	checks.pass("deploy label", "node %s is labelled %s=true", node.Name, c.deployLabel)
+
+	if daemonset == nil {
+		return checks.fail("daemonset", "the DaemonSet of %s does not exist, the operator failed to create it, check its logs for errors in %s", component, c.state), nil
+	}
+	checks.pass("daemonset", "DaemonSet %s exists", daemonset.Name)
+
+	podSpec := &daemonset.Spec.Template.Spec
+	for _, key := range sortedLabelKeys(podSpec.NodeSelector) {
+		nodeValue, ok := nodeLabels[key]
+		if !ok {
+			return checks.fail("node selector", "node %s does not match the nodeSelector of DaemonSet %s, label %s is not set",
+				node.Name, daemonset.Name, key), nil
+		}
+		if nodeValue != podSpec.NodeSelector[key] {
+			return checks.fail("node selector", "node %s does not match the nodeSelector of DaemonSet %s, label %s is %q instead of %q",
+				node.Name, daemonset.Name, key, nodeValue, podSpec.NodeSelector[key]), nil
+		}
+	}
+	checks.pass("node selector", "node %s matches the nodeSelector of DaemonSet %s", node.Name, daemonset.Name)
+
+	if reason := nodeAffinityMismatch(podSpec.Affinity, nodeLabels); reason != "" {
+		return checks.fail("node affinity", "node %s does not match the node affinity of DaemonSet %s, %s", node.Name, daemonset.Name, reason), nil
+	}
+	checks.pass("node affinity", "node %s matches the node affinity of DaemonSet %s", node.Name, daemonset.Name)
+
+	tolerations := append(daemonPodTolerations(podSpec), podSpec.Tolerations...)
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if !toleratesTaint(tolerations, taint) {
+			return checks.fail("taints", "node %s has the taint %s which DaemonSet %s does not tolerate", node.Name, taint.ToString(), daemonset.Name), nil
+		}
+	}
+	checks.pass("taints", "DaemonSet %s tolerates the taints of node %s", daemonset.Name, node.Name)
+
+	return checks, nil
+}
+
+// daemonPodTolerations returns the tolerations the DaemonSet controller adds to the
+// pods of every DaemonSet, so that they run on cordoned nodes and nodes under pressure
+func daemonPodTolerations(podSpec *corev1.PodSpec) []corev1.Toleration {
+	tolerations := []corev1.Toleration{
+		{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}
+	// the network is unavailable to the pods until the network plugin runs, pods using
+	// the host network do not depend on it
+	if podSpec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule})
+	}
+	return tolerations
+}
+
+func toleratesTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeAffinityMismatch returns why nodeLabels do not match the required node affinity,
+// or an empty string if they do
+func nodeAffinityMismatch(affinity *corev1.Affinity, nodeLabels map[string]string) string {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+
+	var reasons []string
+	// the terms are ORed, the requirements of a term are ANDed
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		reason := ""
+		for _, expr := range term.MatchExpressions {
+			requirement, err := labels.NewRequirement(expr.Key, nodeSelectorOperators[expr.Operator], expr.Values)
+			if err != nil {
+				reason = fmt.Sprintf("invalid requirement on %s: %v", expr.Key, err)
+				break
+			}
+			if !requirement.Matches(labels.Set(nodeLabels)) {
+				reason = fmt.Sprintf("label requirement %s is not met", requirement.String())
+				break
+			}
+		}
+		if reason == "" {
+			return ""
+		}
+		reasons = append(reasons, reason)
+	}
+	return strings.Join(reasons, ", ")
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func sortedLabelKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
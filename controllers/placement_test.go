@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"testing"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExplainPlacement(t *testing.T) {
+	disabled := false
+	gpuNode := func(extraLabels map[string]string, taints ...corev1.Taint) *corev1.Node {
+		nodeLabels := map[string]string{
+			"feature.node.kubernetes.io/pci-10de.present": "true",
+			commonGPULabelKey:                    commonGPULabelValue,
+			"xdxct.com/gpu.deploy.device-plugin": "true",
+		}
+		for k, v := range extraLabels {
+			nodeLabels[k] = v
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-node", Labels: nodeLabels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	daemonset := func(mutate func(*corev1.PodSpec)) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "xdxct-device-plugin-daemonset"}}
+		ds.Spec.Template.Spec.NodeSelector = map[string]string{"xdxct.com/gpu.deploy.device-plugin": "true"}
+		ds.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "xdxct.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+		if mutate != nil {
+			mutate(&ds.Spec.Template.Spec)
+		}
+		return ds
+	}
+
+	testCases := []struct {
+		description   string
+		component     string
+		states        []string
+		spec          gpuv1.ClusterPolicySpec
+		node          *corev1.Node
+		daemonset     *appsv1.DaemonSet
+		errorExpected bool
+		// blockedBy is the check expected to fail, empty if none is
+		blockedBy string
+		reason    string
+	}{
+		{
+			description:   "unknown component",
+			component:     "foo",
+			node:          gpuNode(nil),
+			errorExpected: true,
+		},
+		{
+			description: "state not deployed",
+			component:   "driver",
+			node:        gpuNode(nil),
+			blockedBy:   "state",
+			reason:      "the operator does not deploy driver",
+		},
+		{
+			description: "state not configured",
+			component:   "device-plugin",
+			states:      []string{"pre-requisites", "state-container-toolkit"},
+			node:        gpuNode(nil),
+			blockedBy:   "state",
+			reason:      "state-device-plugin is not one of the states set by its --states flag",
+		},
+		{
+			description: "state configured",
+			component:   "driver",
+			states:      []string{"pre-requisites", "state-driver"},
+			node:        gpuNode(nil),
+			blockedBy:   "deploy label",
+			reason:      "node gpu-node is missing the label xdxct.com/gpu.deploy.driver",
+		},
+		{
+			description: "state disabled",
+			component:   "device-plugin",
+			spec:        gpuv1.ClusterPolicySpec{DevicePlugin: gpuv1.DevicePluginSpec{Enabled: &disabled}},
+			node:        gpuNode(nil),
+			blockedBy:   "state",
+			reason:      "spec.devicePlugin.enabled is false",
+		},
+		{
+			description: "no gpu",
+			component:   "device-plugin",
+			node:        &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cpu-node"}},
+			blockedBy:   "gpu node",
+			reason:      "node cpu-node has no GPU detected by NFD",
+		},
+		{
+			description: "not labelled yet",
+			component:   "device-plugin",
+			node:        gpuNode(map[string]string{commonGPULabelKey: "false"}),
+			blockedBy:   "gpu node",
+			reason:      "has a GPU but is not labelled xdxct.com/gpu.present=true",
+		},
+		{
+			description: "operands disabled",
+			component:   "device-plugin",
+			node:        gpuNode(map[string]string{commonOperandsLabelKey: "false"}),
+			blockedBy:   "operands",
+			reason:      "all operands are disabled on node gpu-node",
+		},
+		{
+			// sandbox workloads are disabled, the workload config of the node is ignored
+			description: "vm workload label ignored",
+			component:   "device-plugin",
+			node:        gpuNode(map[string]string{gpuWorkloadConfigLabelKey: gpuWorkloadConfigVMPassthrough}),
+			blockedBy:   "daemonset",
+			reason:      "the DaemonSet of device-plugin does not exist",
+		},
+		{
+			description: "deploy label disabled",
+			component:   "device-plugin",
+			node:        gpuNode(map[string]string{"xdxct.com/gpu.deploy.device-plugin": "false"}),
+			blockedBy:   "deploy label",
+			reason:      "device-plugin is disabled on node gpu-node by the label xdxct.com/gpu.deploy.device-plugin=false",
+		},
+		{
+			description: "missing daemonset",
+			component:   "device-plugin",
+			node:        gpuNode(nil),
+			blockedBy:   "daemonset",
+			reason:      "the DaemonSet of device-plugin does not exist",
+		},
+		{
+			description: "node selector mismatch",
+			component:   "device-plugin",
+			node:        gpuNode(nil),
+			daemonset: daemonset(func(spec *corev1.PodSpec) {
+				spec.NodeSelector["example.com/pool"] = "gpu"
+			}),
+			blockedBy: "node selector",
+			reason:    "label example.com/pool is not set",
+		},
+		{
+			description: "node affinity mismatch",
+			component:   "device-plugin",
+			node:        gpuNode(nil),
+			daemonset: daemonset(func(spec *corev1.PodSpec) {
+				spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "kubernetes.io/arch",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"arm64"},
+							}},
+						}},
+					},
+				}}
+			}),
+			blockedBy: "node affinity",
+			reason:    "kubernetes.io/arch in (arm64)",
+		},
+		{
+			description: "untolerated taint",
+			component:   "device-plugin",
+			node:        gpuNode(nil, corev1.Taint{Key: "dedicated", Value: "ml", Effect: corev1.TaintEffectNoSchedule}),
+			daemonset:   daemonset(nil),
+			blockedBy:   "taints",
+			reason:      "node gpu-node has the taint dedicated=ml:NoSchedule",
+		},
+		{
+			description: "cordoned node",
+			component:   "device-plugin",
+			node: gpuNode(nil,
+				corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule},
+				corev1.Taint{Key: corev1.TaintNodeMemoryPressure, Effect: corev1.TaintEffectNoSchedule}),
+			daemonset: daemonset(nil),
+		},
+		{
+			description: "network unavailable",
+			component:   "device-plugin",
+			node:        gpuNode(nil, corev1.Taint{Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}),
+			daemonset:   daemonset(nil),
+			blockedBy:   "taints",
+			reason:      "node.kubernetes.io/network-unavailable:NoSchedule",
+		},
+		{
+			description: "network unavailable with host network",
+			component:   "device-plugin",
+			node:        gpuNode(nil, corev1.Taint{Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}),
+			daemonset: daemonset(func(podSpec *corev1.PodSpec) {
+				podSpec.HostNetwork = true
+			}),
+		},
+		{
+			description: "scheduled",
+			component:   "device-plugin",
+			node: gpuNode(nil,
+				corev1.Taint{Key: "xdxct.com/gpu", Effect: corev1.TaintEffectNoSchedule},
+				corev1.Taint{Key: "dedicated", Value: "ml", Effect: corev1.TaintEffectPreferNoSchedule}),
+			daemonset: daemonset(nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cp := &gpuv1.ClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "cluster-policy"}, Spec: tc.spec}
+			checks, err := ExplainPlacement(cp, tc.states, tc.component, tc.node, tc.daemonset)
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, checks)
+
+			last := checks[len(checks)-1]
+			for _, check := range checks[:len(checks)-1] {
+				require.True(t, check.Passed, "check %s failed before the last check", check.Check)
+			}
+			if tc.blockedBy == "" {
+				require.True(t, last.Passed, last.Reason)
+				require.Equal(t, "taints", last.Check)
+				return
+			}
+			require.False(t, last.Passed)
+			require.Equal(t, tc.blockedBy, last.Check)
+			require.Contains(t, last.Reason, tc.reason)
+		})
+	}
+}
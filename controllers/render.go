@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	operandassets "github.com/NVIDIA/gpu-operator/assets"
+	"github.com/go-logr/logr"
+	secv1 "github.com/openshift/api/security/v1"
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// RenderOptions configures the offline rendering of the operand objects of a ClusterPolicy
+type RenderOptions struct {
+	// AssetsDir is the directory holding the assets of all states, e.g. "./assets",
+	// the assets embedded in the operator are rendered if empty
+	AssetsDir string
+	// States are the states rendered, DefaultOperandStates() if empty
+	States []string
+	// Namespace the operands are rendered for
+	Namespace string
+	// KubernetesVersion of the cluster, e.g. "v1.27.2"
+	KubernetesVersion string
+	// Nodes of the cluster, used to detect GPU nodes, the container runtime and kernel versions
+	Nodes []corev1.Node
+	// Log receives the log messages of the states, they are discarded if unset
+	Log logr.Logger
+}
+
+// RenderResult holds the objects rendered for a ClusterPolicy
+type RenderResult struct {
+	// Objects created or updated by the states
+	Objects []client.Object
+	// Deleted holds the objects the states delete as their component is disabled
+	Deleted []client.Object
+}
+
+// deleteRecorder records the objects deleted through the client
+type deleteRecorder struct {
+	client.Client
+	deleted []client.Object
+}
+
+func (r *deleteRecorder) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	r.deleted = append(r.deleted, obj.DeepCopyObject().(client.Object))
+	return r.Client.Delete(ctx, obj, opts...)
+}
+
+// applyClient creates the objects it server-side applies which do not exist yet, as
+// the API server does, the fake client only patches existing objects
+type applyClient struct {
+	client.Client
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	if patch.Type() != types.ApplyPatchType || !errors.IsNotFound(err) {
+		return err
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+// renderedObjectLists lists the kinds of objects created by the states, in the order they are rendered
+var renderedObjectLists = []client.ObjectList{
+	&corev1.ServiceAccountList{},
+	&rbacv1.RoleList{},
+	&rbacv1.RoleBindingList{},
+	&rbacv1.ClusterRoleList{},
+	&rbacv1.ClusterRoleBindingList{},
+	&corev1.ConfigMapList{},
+	&corev1.ServiceList{},
+	&nodev1.RuntimeClassList{},
+	&nodev1beta1.RuntimeClassList{},
+	&policyv1beta1.PodSecurityPolicyList{},
+	&secv1.SecurityContextConstraintsList{},
+	&appsv1.DaemonSetList{},
+	&appsv1.DeploymentList{},
+	&corev1.PodList{},
+	&promv1.ServiceMonitorList{},
+	&promv1.PrometheusRuleList{},
+}
+
+// NewRenderScheme returns a scheme holding all types of the objects created by the states
+func NewRenderScheme() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		gpuv1.AddToScheme,
+		promv1.AddToScheme,
+		secv1.Install,
+	} {
+		if err := addToScheme(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Render runs all states of the operator for the given ClusterPolicy against an
+// in-memory fake cluster made of opts.Nodes, and returns the objects the states
+// create, as the operator would apply them, as well as the objects they delete.
+// Image digests are not resolved.
+func Render(ctx context.Context, clusterPolicy *gpuv1.ClusterPolicy, opts RenderOptions) (result *RenderResult, err error) {
+	defer func() {
+		// the transformations panic on assets missing the objects they expect
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to render states: %v", r)
+		}
+	}()
+
+	s, err := NewRenderScheme()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheme: %v", err)
+	}
+
+	cp := clusterPolicy.DeepCopy()
+	cp.ResourceVersion = ""
+	// rendering is offline, image tags are not resolved to digests
+	cp.Spec.Operator.PinImageDigests = nil
+
+	builder := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&gpuv1.ClusterPolicy{}).WithObjects(cp)
+	for i := range opts.Nodes {
+		node := opts.Nodes[i].DeepCopy()
+		node.ResourceVersion = ""
+		builder = builder.WithObjects(node)
+	}
+	c := &deleteRecorder{Client: &applyClient{Client: builder.Build()}}
+
+	log := opts.Log
+	if log.GetSink() == nil {
+		log = logr.Discard()
+	}
+
+	n := ClusterPolicyController{
+		ctx:               ctx,
+		singleton:         cp,
+		operatorNamespace: opts.Namespace,
+		rec:               &ClusterPolicyReconciler{Client: c, Log: log, Scheme: s},
+		k8sVersion:        opts.KubernetesVersion,
+		operatorMetrics:   newOperatorMetrics(),
+	}
+
+	// mimic init() in state_manager.go for the parts which do not need a live cluster
+	hasNFDLabels, gpuNodeCount, err := n.labelGPUNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to label GPU nodes: %v", err)
+	}
+	n.hasGPUNodes = gpuNodeCount != 0
+	n.hasNFDLabels = hasNFDLabels
+
+	err = n.getRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.Spec.Driver.IsEnabled() && cp.Spec.Driver.UsePrecompiledDrivers() {
+		n.kernelVersionMap, err = n.getKernelVersionsMap()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kernel versions of the GPU nodes: %v", err)
+		}
+	}
+
+	var assets fs.FS = operandassets.FS
+	if opts.AssetsDir != "" {
+		assets = os.DirFS(opts.AssetsDir)
+	}
+	states := opts.States
+	if len(states) == 0 {
+		states = defaultOperandStates
+	}
+	loaded, err := loadStateAssets(n.rec, assets, states)
+	if err != nil {
+		return nil, err
+	}
+	n.resources = loaded.resources
+	n.controls = loaded.controls
+	n.stateNames = loaded.stateNames
+	n.dependencies = loaded.dependencies
+
+	for !n.last() {
+		stateName := n.stateNames[n.idx]
+		_, err := n.step()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render state %s: %v", stateName, err)
+		}
+	}
+
+	result = &RenderResult{}
+	for _, obj := range c.deleted {
+		gvk, err := apiutil.GVKForObject(obj, s)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		result.Deleted = append(result.Deleted, obj)
+	}
+
+	for _, list := range renderedObjectLists {
+		list = list.DeepCopyObject().(client.ObjectList)
+		err := c.List(ctx, list)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rendered objects: %v", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract rendered objects: %v", err)
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, s)
+			if err != nil {
+				return nil, err
+			}
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+			// drop the fields set by the fake cluster
+			obj.SetResourceVersion("")
+			result.Objects = append(result.Objects, obj)
+		}
+	}
+
+	// the objects of the kinds without a typed control follow, in the order of their state
+	for _, res := range n.resources {
+		for _, manifest := range res.Objects {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(manifest.GroupVersionKind())
+			namespace := manifest.GetNamespace()
+			if namespace == assetNamespacePlaceholder {
+				namespace = opts.Namespace
+			}
+			err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: manifest.GetName()}, obj)
+			if errors.IsNotFound(err) {
+				// deleted as its state is disabled
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to get rendered %s %s: %v", manifest.GetKind(), manifest.GetName(), err)
+			}
+			obj.SetResourceVersion("")
+			result.Objects = append(result.Objects, obj)
+		}
+	}
+
+	return result, nil
+}
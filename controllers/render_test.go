@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRender(t *testing.T) {
+	cp := &gpuv1.ClusterPolicy{}
+	cp.Name = "cluster-policy"
+	cp.Spec.Driver.Enabled = boolFalse
+	cp.Spec.Toolkit = gpuv1.ToolkitSpec{Repository: "nvcr.io/nvidia/k8s", Image: "container-toolkit", Version: "v1.13.0"}
+	cp.Spec.DevicePlugin = gpuv1.DevicePluginSpec{Repository: "nvcr.io/nvidia", Image: "k8s-device-plugin", Version: "v0.14.0"}
+	cp.Spec.Validator = gpuv1.ValidatorSpec{Repository: "nvcr.io/nvidia/cloud-native", Image: "gpu-operator-validator", Version: "v23.3.0"}
+	// image digests are never resolved when rendering
+	cp.Spec.Operator.PinImageDigests = boolTrue
+
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-node", Labels: nfdLabels},
+		Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{ContainerRuntimeVersion: "containerd://1.7.0"}},
+	}
+
+	opts := RenderOptions{
+		AssetsDir:         filepath.Join(cfg.root, "assets"),
+		Namespace:         "test-operator",
+		KubernetesVersion: "v1.27.2",
+		Nodes:             []corev1.Node{node},
+	}
+	result, err := Render(context.Background(), cp, opts)
+	require.NoError(t, err)
+
+	// the runtime classes of CDI are deleted as CDI is disabled
+	require.Contains(t, renderedNames(result.Deleted), "RuntimeClass/nvidia-cdi")
+	require.NotContains(t, renderedNames(result.Deleted), "DaemonSet/xdxct-device-plugin-daemonset")
+
+	daemonsets := map[string]*appsv1.DaemonSet{}
+	for _, obj := range result.Objects {
+		require.NotEmpty(t, obj.GetObjectKind().GroupVersionKind().Kind)
+		require.Empty(t, obj.GetResourceVersion())
+		if ds, ok := obj.(*appsv1.DaemonSet); ok {
+			daemonsets[ds.Name] = ds
+		}
+	}
+
+	for _, name := range []string{"xdxct-container-toolkit-daemonset", "xdxct-device-plugin-daemonset"} {
+		ds, ok := daemonsets[name]
+		require.True(t, ok, "daemonset %s not rendered", name)
+		require.Equal(t, "test-operator", ds.Namespace)
+		require.NotEmpty(t, ds.Annotations[NvidiaAnnotationHashKey])
+		require.NotContains(t, ds.Spec.Template.Spec.Containers[0].Image, "@")
+	}
+	require.Equal(t, "nvcr.io/nvidia/k8s/container-toolkit:v1.13.0",
+		daemonsets["xdxct-container-toolkit-daemonset"].Spec.Template.Spec.Containers[0].Image)
+
+	// objects of disabled components are reported as deleted
+	cp.Spec.DevicePlugin.Enabled = boolFalse
+	result, err = Render(context.Background(), cp, opts)
+	require.NoError(t, err)
+	require.Contains(t, renderedNames(result.Deleted), "DaemonSet/xdxct-device-plugin-daemonset")
+	for _, obj := range result.Objects {
+		require.NotEqual(t, "xdxct-device-plugin-daemonset", obj.GetName())
+	}
+
+	// states referencing a missing assets directory are reported as errors
+	opts.AssetsDir = filepath.Join(cfg.root, "does-not-exist")
+	_, err = Render(context.Background(), cp, opts)
+	require.Error(t, err)
+}
+
+func renderedNames(objs []client.Object) []string {
+	names := []string{}
+	for _, obj := range objs {
+		names = append(names, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+	}
+	return names
+}
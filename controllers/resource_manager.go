@@ -1,8 +1,8 @@
 package controllers
 
 import (
-	"os"
-	"path/filepath"
+	"fmt"
+	"io/fs"
 	"regexp"
 	"sort"
 	"strings"
@@ -17,6 +17,7 @@ import (
 
 	secv1 "github.com/openshift/api/security/v1"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -49,137 +50,141 @@ type Resources struct {
 	PodSecurityPolicy          policyv1beta1.PodSecurityPolicy
 	RuntimeClasses             []nodev1.RuntimeClass
 	PrometheusRule             promv1.PrometheusRule
+	// Objects are the manifests of the kinds without a typed control, applied as is
+	Objects []unstructured.Unstructured
 }
 
-func filePathWalkDir(n *ClusterPolicyController, root string) ([]string, error) {
+// getAssetsFrom returns the manifests of all files under path in assets, sorted by path
+func getAssetsFrom(assets fs.FS, path string) ([]assetsFromFile, error) {
 	var files []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(assets, path, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			n.rec.Log.V(1).Info("error in filepath.Walk on %s: %v", root, err)
-			return nil
+			return err
 		}
-		if !info.IsDir() {
+		if !d.IsDir() {
 			files = append(files, path)
 		}
 		return nil
 	})
-	return files, err
-}
-
-func getAssetsFrom(n *ClusterPolicyController, path string) []assetsFromFile {
-	manifests := []assetsFromFile{}
-	files, err := filePathWalkDir(n, path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	sort.Strings(files)
-	for _, file := range files {
 
-		buffer, err := os.ReadFile(file)
+	manifests := []assetsFromFile{}
+	for _, file := range files {
+		buffer, err := fs.ReadFile(assets, file)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		manifests = append(manifests, buffer)
 	}
-	return manifests
+	return manifests, nil
 }
 
-func addResourcesControls(n *ClusterPolicyController, path string) (Resources, controlFunc) {
+// addResourcesControls decodes the manifests under path in assets into the resources
+// of a state, and returns the controls applying them
+func addResourcesControls(n *ClusterPolicyController, assets fs.FS, path string) (Resources, controlFunc, error) {
 	res := Resources{}
 	ctrl := controlFunc{}
 
 	n.rec.Log.Info("Getting assets from: ", "path:", path)
-	manifests := getAssetsFrom(n, path)
+	manifests, err := getAssetsFrom(assets, path)
+	if err != nil {
+		return res, ctrl, fmt.Errorf("failed to read assets of %s: %v", path, err)
+	}
 
 	s := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme,
 		scheme.Scheme, json.SerializerOptions{Yaml: true, Pretty: false, Strict: false})
-	reg, _ := regexp.Compile(`\b(\w*kind:\w*)\B.*\b`)
 
 	for _, m := range manifests {
-		kind := reg.FindString(string(m))
-		slce := strings.Split(kind, ":")
-		kind = strings.TrimSpace(slce[1])
+		kind := assetKind(m)
 
 		n.rec.Log.V(1).Info("Looking for ", "Kind", kind, "in path:", path)
 
 		switch kind {
 		case "ServiceAccount":
-			_, _, err := s.Decode(m, nil, &res.ServiceAccount)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.ServiceAccount)
 			ctrl = append(ctrl, ServiceAccount)
 		case "Role":
-			_, _, err := s.Decode(m, nil, &res.Role)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.Role)
 			ctrl = append(ctrl, Role)
 		case "RoleBinding":
-			_, _, err := s.Decode(m, nil, &res.RoleBinding)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.RoleBinding)
 			ctrl = append(ctrl, RoleBinding)
 		case "ClusterRole":
-			_, _, err := s.Decode(m, nil, &res.ClusterRole)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.ClusterRole)
 			ctrl = append(ctrl, ClusterRole)
 		case "ClusterRoleBinding":
-			_, _, err := s.Decode(m, nil, &res.ClusterRoleBinding)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.ClusterRoleBinding)
 			ctrl = append(ctrl, ClusterRoleBinding)
 		case "ConfigMap":
 			cm := corev1.ConfigMap{}
-			_, _, err := s.Decode(m, nil, &cm)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &cm)
 			res.ConfigMaps = append(res.ConfigMaps, cm)
 			// only add the ctrl function when the first ConfigMap is added for this component
 			if len(res.ConfigMaps) == 1 {
 				ctrl = append(ctrl, ConfigMaps)
 			}
 		case "DaemonSet":
-			_, _, err := s.Decode(m, nil, &res.DaemonSet)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.DaemonSet)
 			ctrl = append(ctrl, DaemonSet)
 		case "Deployment":
-			_, _, err := s.Decode(m, nil, &res.Deployment)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.Deployment)
 			ctrl = append(ctrl, Deployment)
 		case "Service":
-			_, _, err := s.Decode(m, nil, &res.Service)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.Service)
 			ctrl = append(ctrl, Service)
 		case "ServiceMonitor":
-			_, _, err := s.Decode(m, nil, &res.ServiceMonitor)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.ServiceMonitor)
 			ctrl = append(ctrl, ServiceMonitor)
 		case "SecurityContextConstraints":
-			_, _, err := s.Decode(m, nil, &res.SecurityContextConstraints)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.SecurityContextConstraints)
 			ctrl = append(ctrl, SecurityContextConstraints)
 		case "RuntimeClass":
 			rt := nodev1.RuntimeClass{}
-			_, _, err := s.Decode(m, nil, &rt)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &rt)
 			res.RuntimeClasses = append(res.RuntimeClasses, rt)
 			// only add the ctrl function when the first RuntimeClass is added
 			if len(res.RuntimeClasses) == 1 {
 				ctrl = append(ctrl, RuntimeClasses)
 			}
 		case "PodSecurityPolicy":
-			_, _, err := s.Decode(m, nil, &res.PodSecurityPolicy)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.PodSecurityPolicy)
 			ctrl = append(ctrl, PodSecurityPolicy)
 		case "PrometheusRule":
-			_, _, err := s.Decode(m, nil, &res.PrometheusRule)
-			panicIfError(err)
+			_, _, err = s.Decode(m, nil, &res.PrometheusRule)
 			ctrl = append(ctrl, PrometheusRule)
-		default:
+		case "":
 			n.rec.Log.Info("Unknown Resource", "Manifest", m, "Kind", kind)
+		default:
+			var obj unstructured.Unstructured
+			obj, err = decodeUnstructured(m)
+			if err != nil {
+				break
+			}
+			res.Objects = append(res.Objects, obj)
+			// only add the ctrl function when the first object is added
+			if len(res.Objects) == 1 {
+				ctrl = append(ctrl, Objects)
+			}
+		}
+		if err != nil {
+			return res, ctrl, fmt.Errorf("failed to decode %s in %s: %v", kind, path, err)
 		}
-
 	}
 
-	return res, ctrl
+	return res, ctrl, nil
 }
 
-func panicIfError(err error) {
-	if err != nil {
-		panic(err)
+var assetKindRegexp = regexp.MustCompile(`\b(\w*kind:\w*)\B.*\b`)
+
+// assetKind returns the value of the first kind field of the manifest, or an
+// empty string if it has none
+func assetKind(m assetsFromFile) string {
+	kind := assetKindRegexp.FindString(string(m))
+	if kind == "" {
+		return ""
 	}
+	return strings.TrimSpace(strings.SplitN(kind, ":", 2)[1])
 }
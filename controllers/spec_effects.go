@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpecFieldEffect is a setting of an operand controlled by a field of the ClusterPolicy spec
+type SpecFieldEffect struct {
+	// Operand is the name of the operand, as accepted by ExplainPlacement, or empty
+	// if the field only affects the operator itself
+	Operand string
+	// Setting is the env var, container or pod setting the field ends up controlling
+	Setting string
+	// Restarts is true if changing the field changes the pod template of the operand,
+	// which restarts its pods following the update strategy of its DaemonSet
+	Restarts bool
+}
+
+// specFieldEffects maps the paths of the ClusterPolicy spec fields, relative to spec,
+// to the settings the transformations in daemonsetTransformations derive from them.
+// It must be kept in sync with the transformations
+var specFieldEffects = buildSpecFieldEffects()
+
+func buildSpecFieldEffects() map[string][]SpecFieldEffect {
+	effects := map[string][]SpecFieldEffect{
+		"operator.runtimeClass": {
+			{Operand: "container-toolkit", Setting: "CONTAINERD_RUNTIME_CLASS env of the toolkit container, on containerd", Restarts: true},
+			{Operand: "device-plugin", Setting: "runtimeClassName of the pod", Restarts: true},
+			{Operand: "gpu-feature-discovery", Setting: "runtimeClassName of the pod", Restarts: true},
+			{Operand: "operator-validator", Setting: "runtimeClassName of the pod", Restarts: true},
+		},
+		"operator.imageRegistryMirrors": forAllOperands("registry of the images of all containers", true),
+		"operator.pinImageDigests":      forAllOperands("images of all containers, deployed by digest instead of tag", true),
+		"operator.paused": {
+			{Setting: "reconciliation of all states, nothing is created, updated or deleted while paused"},
+		},
+		"daemonsets.labels":            forAllOperands("labels of the pod template", true),
+		"daemonsets.annotations":       forAllOperands("annotations of the pod template", true),
+		"daemonsets.tolerations":       forAllOperands("tolerations of the pod", true),
+		"daemonsets.priorityClassName": forAllOperands("priorityClassName of the pod", true),
+		"daemonsets.updateStrategy":    forAllOperands("updateStrategy of the DaemonSet", false),
+		"daemonsets.rollingUpdate":     forAllOperands("rollingUpdate.maxUnavailable of the DaemonSet", false),
+		"psp.enabled": {
+			{Setting: "PodSecurityPolicies of the operands and Pod Security Admission labels of the operator namespace"},
+		},
+		"psa.enabled": {
+			{Setting: "Pod Security Admission labels of the operator namespace"},
+		},
+		"toolkit.installDir": {
+			{Operand: "container-toolkit", Setting: fmt.Sprintf("%s env of the toolkit container and path of the toolkit-install-dir hostPath volume", ToolkitInstallDirEnvName), Restarts: true},
+			{Operand: "device-plugin", Setting: fmt.Sprintf("%s env of the device plugin container, when cdi.enabled", NvidiaCTKPathEnvName), Restarts: true},
+		},
+		"devicePlugin.config.name": {
+			{Operand: "device-plugin", Setting: "CONFIG_FILE=/config/config.yaml env, ConfigMap volume, config-manager-init and config-manager containers and shareProcessNamespace of the pod", Restarts: true},
+			{Operand: "gpu-feature-discovery", Setting: "CONFIG_FILE=/config/config.yaml env, ConfigMap volume, config-manager-init and config-manager containers and shareProcessNamespace of the pod", Restarts: true},
+		},
+		"devicePlugin.config.default": {
+			{Operand: "device-plugin", Setting: "DEFAULT_CONFIG env of the config-manager-init and config-manager containers", Restarts: true},
+			{Operand: "gpu-feature-discovery", Setting: "DEFAULT_CONFIG env of the config-manager-init and config-manager containers", Restarts: true},
+		},
+		"validator.plugin.env": {
+			{Operand: "operator-validator", Setting: "env of the plugin-validation init container", Restarts: true},
+		},
+		"validator.toolkit.env": {
+			{Operand: "operator-validator", Setting: "env of the toolkit-validation init container", Restarts: true},
+			{Operand: "gpu-feature-discovery", Setting: "env of the toolkit-validation init container", Restarts: true},
+		},
+		"validator.driver.env": {
+			{Operand: "operator-validator", Setting: "env of the driver-validation init container", Restarts: true},
+		},
+		"cdi.enabled": {
+			{Operand: "container-toolkit", Setting: fmt.Sprintf("%s=true, %s=nvidia.cdi.k8s.io/ and %s=config env of the toolkit container",
+				CDIEnabledEnvName, NvidiaCtrRuntimeCDIPrefixesEnvName, CrioConfigModeEnvName), Restarts: true},
+			{Operand: "device-plugin", Setting: fmt.Sprintf("%s=true, %s=envvar,cdi-annotations, %s=nvidia.cdi.k8s.io/ and %s env of the device plugin container",
+				CDIEnabledEnvName, DeviceListStrategyEnvName, CDIAnnotationPrefixEnvName, NvidiaCTKPathEnvName), Restarts: true},
+		},
+		"cdi.default": {
+			{Operand: "container-toolkit", Setting: fmt.Sprintf("%s=cdi env of the toolkit container, when cdi.enabled", NvidiaCtrRuntimeModeEnvName), Restarts: true},
+		},
+		"driver.rdma.enabled": {
+			{Operand: "driver", Setting: fmt.Sprintf("mofed-validation init container and %s env", GPUDirectRDMAEnabledEnvName), Restarts: true},
+		},
+		"driver.rdma.useHostMofed": {
+			{Operand: "driver", Setting: fmt.Sprintf("%s env of the mofed-validation init container", UseHostMOFEDEnvName), Restarts: true},
+		},
+	}
+
+	for component, c := range operandComponents {
+		for path, componentEffects := range operandSpecEffects(component, c.specField) {
+			effects[path] = append(effects[path], componentEffects...)
+		}
+	}
+
+	// the validator has no enabled field, it is deployed with the operator
+	delete(effects, "validator.enabled")
+	// TransformToolkit does not set the args of the toolkit container
+	delete(effects, "toolkit.args")
+
+	// the validator image is also used by the validation init containers, including
+	// those of the other operands
+	for _, field := range []string{"repository", "image", "version", "imagePullPolicy"} {
+		for _, component := range []string{"driver", "gpu-feature-discovery", "operator-validator"} {
+			effects["validator."+field] = append(effects["validator."+field],
+				SpecFieldEffect{Operand: component, Setting: "image of the validation init containers", Restarts: true})
+		}
+	}
+	return effects
+}
+
+// operandSpecEffects returns the effects of the fields every operand spec has, under specField
+func operandSpecEffects(component string, specField string) map[string][]SpecFieldEffect {
+	effect := func(setting string, restarts bool) []SpecFieldEffect {
+		return []SpecFieldEffect{{Operand: component, Setting: setting, Restarts: restarts}}
+	}
+	image := effect("image of the main container", true)
+	if component == "device-plugin" {
+		image = effect("image of the main, config-manager-init and config-manager containers", true)
+	}
+	return map[string][]SpecFieldEffect{
+		specField + ".enabled":           effect("whether the DaemonSet is deployed, it is deleted when disabled", false),
+		specField + ".paused":            effect("reconciliation of the state, the DaemonSet is neither updated nor deleted while paused", false),
+		specField + ".repository":        image,
+		specField + ".image":             image,
+		specField + ".version":           image,
+		specField + ".imagePullPolicy":   effect("imagePullPolicy of the main container", true),
+		specField + ".imagePullSecrets":  effect("imagePullSecrets of the pod", true),
+		specField + ".resources":         effect("resources of all containers", true),
+		specField + ".args":              effect("args of the main container", true),
+		specField + ".env":               effect("env of the main container", true),
+		specField + ".nodeSelector":      effect("nodeSelector of the pod, merged with the deploy label", true),
+		specField + ".affinity":          effect("affinity of the pod", true),
+		specField + ".tolerations":       effect("tolerations of the pod, added to daemonsets.tolerations", true),
+		specField + ".priorityClassName": effect("priorityClassName of the pod, overriding daemonsets.priorityClassName", true),
+		specField + ".updateStrategy":    effect("updateStrategy of the DaemonSet, overriding daemonsets.updateStrategy", false),
+		specField + ".rollingUpdate":     effect("rollingUpdate.maxUnavailable of the DaemonSet, overriding daemonsets.rollingUpdate", false),
+	}
+}
+
+// forAllOperands returns the same effect on every operand
+func forAllOperands(setting string, restarts bool) []SpecFieldEffect {
+	var effects []SpecFieldEffect
+	for _, component := range OperandComponents() {
+		effects = append(effects, SpecFieldEffect{Operand: component, Setting: setting, Restarts: restarts})
+	}
+	return effects
+}
+
+// SpecFieldEffects returns the settings controlled by the spec field at path, relative to
+// spec. The effects of a struct field are those of all the fields under it, and a field
+// without known effects has those of the closest parent field which has some, such as
+// the fields of a Kubernetes type set as a whole
+func SpecFieldEffects(path string) []SpecFieldEffect {
+	path = strings.TrimPrefix(path, "spec.")
+	if effects, ok := specFieldEffects[path]; ok {
+		return sortedEffects(effects)
+	}
+
+	var effects []SpecFieldEffect
+	for field, fieldEffects := range specFieldEffects {
+		if strings.HasPrefix(field, path+".") {
+			effects = append(effects, fieldEffects...)
+		}
+	}
+	if len(effects) > 0 {
+		return sortedEffects(effects)
+	}
+
+	for parent := path; strings.Contains(parent, "."); {
+		parent = parent[:strings.LastIndex(parent, ".")]
+		if effects, ok := specFieldEffects[parent]; ok {
+			return sortedEffects(effects)
+		}
+	}
+	return nil
+}
+
+// sortedEffects returns effects sorted by operand and setting, without duplicates
+func sortedEffects(effects []SpecFieldEffect) []SpecFieldEffect {
+	seen := map[SpecFieldEffect]bool{}
+	var sorted []SpecFieldEffect
+	for _, effect := range effects {
+		if !seen[effect] {
+			seen[effect] = true
+			sorted = append(sorted, effect)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Operand != sorted[j].Operand {
+			return sorted[i].Operand < sorted[j].Operand
+		}
+		return sorted[i].Setting < sorted[j].Setting
+	})
+	return sorted
+}
+
+// OperandDeployed returns whether the operator deploying the given states deploys the
+// state of the given operand, states are DefaultOperandStates() if empty
+func OperandDeployed(component string, states []string) bool {
+	c, ok := operandComponents[component]
+	if !ok {
+		return false
+	}
+	if len(states) == 0 {
+		states = defaultOperandStates
+	}
+	for _, state := range states {
+		if state == c.state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// specField returns whether path names a field of the ClusterPolicy spec
+func specField(t reflect.Type, path string) bool {
+	name, rest, _ := strings.Cut(path, ".")
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && len(tag) > 1 && tag[1] == "inline" {
+			if specField(sf.Type, path) {
+				return true
+			}
+			continue
+		}
+		if tag[0] != name {
+			continue
+		}
+		return rest == "" || specField(sf.Type, rest)
+	}
+	return false
+}
+
+func TestSpecFieldEffectsTable(t *testing.T) {
+	for path, effects := range specFieldEffects {
+		require.True(t, specField(reflect.TypeOf(gpuv1.ClusterPolicySpec{}), path), "%s is not a field of the ClusterPolicy spec", path)
+		for _, effect := range effects {
+			if effect.Operand != "" {
+				require.Contains(t, operandComponents, effect.Operand, "%s has an effect on an unknown operand", path)
+			}
+			require.NotEmpty(t, effect.Setting, path)
+		}
+	}
+}
+
+func TestSpecFieldEffects(t *testing.T) {
+	testCases := []struct {
+		description string
+		path        string
+		// expected maps the operands expected to be affected to a substring of the setting
+		expected map[string]string
+		restarts bool
+	}{
+		{
+			description: "field",
+			path:        "cdi.default",
+			expected:    map[string]string{"container-toolkit": "NVIDIA_CONTAINER_RUNTIME_MODE=cdi"},
+			restarts:    true,
+		},
+		{
+			description: "spec prefix",
+			path:        "spec.toolkit.installDir",
+			expected: map[string]string{
+				"container-toolkit": "ROOT env",
+				"device-plugin":     "NVIDIA_CTK_PATH",
+			},
+			restarts: true,
+		},
+		{
+			description: "struct field",
+			path:        "devicePlugin.config",
+			expected: map[string]string{
+				"device-plugin":         "CONFIG_FILE=/config/config.yaml",
+				"gpu-feature-discovery": "DEFAULT_CONFIG",
+			},
+			restarts: true,
+		},
+		{
+			description: "field of a kubernetes type",
+			path:        "daemonsets.tolerations.key",
+			expected:    map[string]string{"device-plugin": "tolerations of the pod"},
+			restarts:    true,
+		},
+		{
+			description: "no restart",
+			path:        "devicePlugin.updateStrategy",
+			expected:    map[string]string{"device-plugin": "updateStrategy of the DaemonSet"},
+		},
+		{
+			description: "operator only",
+			path:        "operator.paused",
+			expected:    map[string]string{"": "reconciliation of all states"},
+		},
+		{
+			description: "no effect",
+			path:        "operator.defaultRuntime",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			effects := SpecFieldEffects(tc.path)
+			if len(tc.expected) == 0 {
+				require.Empty(t, effects)
+				return
+			}
+			for operand, setting := range tc.expected {
+				found := false
+				for _, effect := range effects {
+					if effect.Operand == operand && strings.Contains(effect.Setting, setting) {
+						found = true
+						require.Equal(t, tc.restarts, effect.Restarts, effect.Setting)
+					}
+				}
+				require.True(t, found, "no effect on %q containing %q in %v", operand, setting, effects)
+			}
+		})
+	}
+}
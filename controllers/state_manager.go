@@ -2,24 +2,22 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"strings"
+	"sync"
 
 	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
-	secv1 "github.com/openshift/api/security/v1"
-	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/NVIDIA/k8s-operator-libs/pkg/upgrade"
 
 	"github.com/go-logr/logr"
 	apiconfigv1 "github.com/openshift/api/config/v1"
-	apiimagev1 "github.com/openshift/api/image/v1"
 	configv1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
-	"golang.org/x/mod/semver"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
-	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -62,6 +60,10 @@ const (
 	driverAutoUpgradeAnnotationKey = "xdxct.com/gpu-driver-upgrade-enabled"
 	commonDriverDaemonsetName      = "nvidia-driver-daemonset"
 	commonVGPUManagerDaemonsetName = "nvidia-vgpu-manager-daemonset"
+
+	// podSecurityOriginalLabelsAnnotationKey records the pod security labels of the
+	// operator namespace before they were overwritten, so they can be restored on uninstall
+	podSecurityOriginalLabelsAnnotationKey = "xdxct.com/pod-security-original-labels"
 )
 
 var (
@@ -100,10 +102,118 @@ var gpuNodeLabels = map[string]string{
 	"feature.node.kubernetes.io/pci-0300_10de.present": "true",
 }
 
+// operandStates lists all states in the order they are added, named after their assets directory
+var operandStates = []string{
+	"pre-requisites",
+	"state-driver",
+	"state-container-toolkit",
+	"state-operator-validation",
+	"state-device-plugin",
+	"gpu-feature-discovery",
+	"state-node-status-exporter",
+}
+
+// defaultOperandStates are the states the operator deploys unless configured otherwise
+var defaultOperandStates = []string{
+	"pre-requisites",
+	"state-container-toolkit",
+	"state-device-plugin",
+}
+
+// DefaultOperandStates returns the states the operator deploys unless configured otherwise
+func DefaultOperandStates() []string {
+	return append([]string{}, defaultOperandStates...)
+}
+
+// ParseOperandStates parses the comma-separated list of states of the --states flag of
+// the operator and validates it
+func ParseOperandStates(s string) ([]string, error) {
+	var states []string
+	for _, state := range strings.Split(s, ",") {
+		if state = strings.TrimSpace(state); state != "" {
+			states = append(states, state)
+		}
+	}
+	if err := ValidateOperandStates(states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ValidateOperandStates checks that states only holds known states, without duplicates
+func ValidateOperandStates(states []string) error {
+	seen := map[string]bool{}
+	for _, state := range states {
+		if operandStateIndex(state) < 0 {
+			return fmt.Errorf("unknown state %q, must be one of [%s]", state, strings.Join(operandStates, ", "))
+		}
+		if seen[state] {
+			return fmt.Errorf("state %q is listed more than once", state)
+		}
+		seen[state] = true
+	}
+	return nil
+}
+
+// operandStateIndex returns the index of state in operandStates, -1 if it is unknown
+func operandStateIndex(state string) int {
+	for i, name := range operandStates {
+		if name == state {
+			return i
+		}
+	}
+	return -1
+}
+
+// stateDependencies lists the states each state waits for before it is reconciled.
+// A state is skipped while one of its dependencies is not ready, and dependencies
+// on states the operator does not deploy are ignored
+var stateDependencies = map[string][]string{
+	"state-driver":               {"pre-requisites"},
+	"state-container-toolkit":    {"pre-requisites", "state-driver"},
+	"state-operator-validation":  {"state-container-toolkit"},
+	"state-device-plugin":        {"state-container-toolkit"},
+	"gpu-feature-discovery":      {"state-container-toolkit"},
+	"state-node-status-exporter": {"state-container-toolkit"},
+}
+
+// stateAssets are the resources and controls of the states loaded from the assets.
+// They are loaded once and shared by all reconciliations, which only read them
+type stateAssets struct {
+	resources    []Resources
+	controls     []controlFunc
+	stateNames   []string
+	dependencies [][]int
+}
+
+// loadStateAssets loads the given states from assets, in the order of operandStates
+func loadStateAssets(reconciler *ClusterPolicyReconciler, assets fs.FS, states []string) (*stateAssets, error) {
+	err := ValidateOperandStates(states)
+	if err != nil {
+		return nil, err
+	}
+	enabled := map[string]bool{}
+	for _, state := range states {
+		enabled[state] = true
+	}
+
+	n := &ClusterPolicyController{rec: reconciler}
+	for _, state := range operandStates {
+		if !enabled[state] {
+			continue
+		}
+		err := addState(n, assets, state, stateDependencies[state]...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &stateAssets{resources: n.resources, controls: n.controls, stateNames: n.stateNames, dependencies: n.dependencies}, nil
+}
+
 type state interface {
 	init(*ClusterPolicyReconciler, *gpuv1.ClusterPolicy)
 	step()
-	validate()
+	validate() error
 	last()
 }
 
@@ -139,6 +249,7 @@ type ClusterPolicyController struct {
 	resources            []Resources
 	controls             []controlFunc
 	stateNames           []string
+	dependencies         [][]int
 	operatorMetrics      *OperatorMetrics
 	rec                  *ClusterPolicyReconciler
 	idx                  int
@@ -152,15 +263,44 @@ type ClusterPolicyController struct {
 	hasGPUNodes    bool
 	hasNFDLabels   bool
 	sandboxEnabled bool
+
+	imageDigests   imageDigests
+	digestResolver imageDigestResolver
 }
 
-func addState(n *ClusterPolicyController, path string) error {
-	// TODO check for path
-	res, ctrl := addResourcesControls(n, path)
+// addState loads the state from its assets directory dir in assets. The states it
+// depends on must be added before it, which keeps the dependencies free of cycles
+func addState(n *ClusterPolicyController, assets fs.FS, dir string, dependsOn ...string) error {
+	dir = path.Clean(dir)
+	stateName := path.Base(dir)
+	var dependencies []int
+	for _, dependency := range dependsOn {
+		idx := -1
+		for i, name := range n.stateNames {
+			if name == dependency {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			dependencies = append(dependencies, idx)
+			continue
+		}
+		if operandStateIndex(dependency) > operandStateIndex(stateName) {
+			return fmt.Errorf("state %s depends on %s, which must be added before it", stateName, dependency)
+		}
+		// the operator does not deploy the dependency
+	}
+
+	res, ctrl, err := addResourcesControls(n, assets, dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state %s: %v", stateName, err)
+	}
 
 	n.controls = append(n.controls, ctrl)
 	n.resources = append(n.resources, res)
-	n.stateNames = append(n.stateNames, filepath.Base(path))
+	n.stateNames = append(n.stateNames, stateName)
+	n.dependencies = append(n.dependencies, dependencies)
 
 	return nil
 }
@@ -422,6 +562,9 @@ func (n *ClusterPolicyController) applyDriverAutoUpgradeAnnotation() error {
 			continue
 		}
 		// update annotation
+		if node.ObjectMeta.Annotations == nil {
+			node.ObjectMeta.Annotations = map[string]string{}
+		}
 		node.ObjectMeta.Annotations[driverAutoUpgradeAnnotationKey] = value
 		if value == "null" {
 			// remove annotation if value is null
@@ -553,7 +696,7 @@ func getRuntimeString(node corev1.Node) (gpuv1.Runtime, error) {
 
 func (n *ClusterPolicyController) setPodSecurityLabelsForNamespace() error {
 	ctx := n.ctx
-	namespaceName := clusterPolicyCtrl.operatorNamespace
+	namespaceName := n.operatorNamespace
 
 	ns := &corev1.Namespace{}
 	opts := client.ObjectKey{Name: namespaceName}
@@ -571,6 +714,25 @@ func (n *ClusterPolicyController) setPodSecurityLabelsForNamespace() error {
 		ns.ObjectMeta.Labels = make(map[string]string)
 		modified = true
 	}
+	// remember the labels set before the operator took over, once
+	if _, ok := ns.ObjectMeta.Annotations[podSecurityOriginalLabelsAnnotationKey]; !ok {
+		original := map[string]string{}
+		for _, mode := range podSecurityModes {
+			key := podSecurityLabelPrefix + mode
+			if val, ok := ns.ObjectMeta.Labels[key]; ok {
+				original[key] = val
+			}
+		}
+		data, err := json.Marshal(original)
+		if err != nil {
+			return fmt.Errorf("unable to record pod security labels of namespace %s: %v", namespaceName, err)
+		}
+		if ns.ObjectMeta.Annotations == nil {
+			ns.ObjectMeta.Annotations = make(map[string]string)
+		}
+		ns.ObjectMeta.Annotations[podSecurityOriginalLabelsAnnotationKey] = string(data)
+		modified = true
+	}
 	for _, mode := range podSecurityModes {
 		key := podSecurityLabelPrefix + mode
 		if val, ok := ns.ObjectMeta.Labels[key]; !ok || (val != podSecurityLevelPrivileged) {
@@ -591,9 +753,111 @@ func (n *ClusterPolicyController) setPodSecurityLabelsForNamespace() error {
 	return nil
 }
 
+// restorePodSecurityLabelsForNamespace reverts the pod security labels of
+// the operator namespace to the values recorded by setPodSecurityLabelsForNamespace
+func (n *ClusterPolicyController) restorePodSecurityLabelsForNamespace() error {
+	ctx := n.ctx
+	namespaceName := n.operatorNamespace
+
+	ns := &corev1.Namespace{}
+	opts := client.ObjectKey{Name: namespaceName}
+	err := n.rec.Client.Get(ctx, opts, ns)
+	if err != nil {
+		return fmt.Errorf("ERROR: could not get Namespace %s from client: %v", namespaceName, err)
+	}
+
+	data, ok := ns.ObjectMeta.Annotations[podSecurityOriginalLabelsAnnotationKey]
+	if !ok {
+		// pod security labels were never modified by the operator
+		return nil
+	}
+	original := map[string]string{}
+	err = json.Unmarshal([]byte(data), &original)
+	if err != nil {
+		return fmt.Errorf("unable to parse annotation %s of namespace %s: %v", podSecurityOriginalLabelsAnnotationKey, namespaceName, err)
+	}
+
+	patch := client.MergeFrom(ns.DeepCopy())
+	for _, mode := range podSecurityModes {
+		key := podSecurityLabelPrefix + mode
+		if val, ok := original[key]; ok {
+			ns.ObjectMeta.Labels[key] = val
+		} else {
+			delete(ns.ObjectMeta.Labels, key)
+		}
+	}
+	delete(ns.ObjectMeta.Annotations, podSecurityOriginalLabelsAnnotationKey)
+
+	err = n.rec.Client.Patch(ctx, ns, patch)
+	if err != nil {
+		return fmt.Errorf("unable to restore pod security labels of namespace %s: %v", namespaceName, err)
+	}
+	return nil
+}
+
+// removeOperatorNodeLabels removes all labels set by the operator from the
+// provided map of node labels. It returns true if the labels map has been modified.
+func removeOperatorNodeLabels(labels map[string]string) bool {
+	modified := removeAllGPUStateLabels(labels)
+	for _, key := range []string{commonGPULabelKey, upgrade.GetUpgradeStateLabelKey()} {
+		if _, ok := labels[key]; ok {
+			delete(labels, key)
+			modified = true
+		}
+	}
+	return modified
+}
+
+// cleanupNodes removes all labels and annotations owned by the operator from every node
+func (n *ClusterPolicyController) cleanupNodes() error {
+	list := &corev1.NodeList{}
+	err := n.rec.Client.List(n.ctx, list)
+	if err != nil {
+		return fmt.Errorf("Unable to list nodes to remove labels, err %s", err.Error())
+	}
+
+	for i := range list.Items {
+		node := &list.Items[i]
+		patch := client.MergeFrom(node.DeepCopy())
+
+		modified := removeOperatorNodeLabels(node.ObjectMeta.Labels)
+		if _, ok := node.ObjectMeta.Annotations[driverAutoUpgradeAnnotationKey]; ok {
+			delete(node.ObjectMeta.Annotations, driverAutoUpgradeAnnotationKey)
+			modified = true
+		}
+		if !modified {
+			continue
+		}
+
+		n.rec.Log.Info("Removing GPU Operator labels and annotations from node", "NodeName", node.ObjectMeta.Name)
+		err = n.rec.Client.Patch(n.ctx, node, patch)
+		if err != nil {
+			return fmt.Errorf("Unable to remove GPU Operator labels from node %s, err %s", node.ObjectMeta.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// cleanup reverts all changes the operator made to nodes and to the operator
+// namespace. It is called before the finalizer of the active ClusterPolicy is released.
+func (n *ClusterPolicyController) cleanup(ctx context.Context, reconciler *ClusterPolicyReconciler) error {
+	n.ctx = ctx
+	n.rec = reconciler
+
+	err := n.cleanupNodes()
+	if err != nil {
+		return err
+	}
+
+	if n.operatorNamespace == "" {
+		return nil
+	}
+	return n.restorePodSecurityLabelsForNamespace()
+}
+
 func (n *ClusterPolicyController) ocpEnsureNamespaceMonitoring() error {
 	ctx := n.ctx
-	namespaceName := clusterPolicyCtrl.operatorNamespace
+	namespaceName := n.operatorNamespace
 
 	if namespaceName != ocpSuggestedNamespace {
 		// The GPU Operator is not installed in the suggested
@@ -689,49 +953,37 @@ func (n *ClusterPolicyController) getRuntime() error {
 	return nil
 }
 
-func (n *ClusterPolicyController) init(ctx context.Context, reconciler *ClusterPolicyReconciler, clusterPolicy *gpuv1.ClusterPolicy) error {
+// init prepares the controller for a single reconciliation of clusterPolicy, with the
+// states and caches kept by reconciler
+func (n *ClusterPolicyController) init(ctx context.Context, reconciler *ClusterPolicyReconciler, clusterPolicy *gpuv1.ClusterPolicy, metrics *OperatorMetrics) error {
 	n.singleton = clusterPolicy
 	n.ctx = ctx
 	n.rec = reconciler
 	n.idx = 0
+	n.operatorNamespace = reconciler.OperatorNamespace
+	n.operatorMetrics = metrics
 
-	if len(n.controls) == 0 {
-		clusterPolicyCtrl.operatorNamespace = os.Getenv("OPERATOR_NAMESPACE")
-
-		if clusterPolicyCtrl.operatorNamespace == "" {
-			n.rec.Log.Error(nil, "OPERATOR_NAMESPACE environment variable not set, cannot proceed")
-			// we cannot do anything without the operator namespace,
-			// let the operator Pod run into `CrashloopBackOff`
-
-			os.Exit(1)
-		}
-
-		k8sVersion, err := KubernetesVersion()
-		if err != nil {
-			return err
-		}
-		if !semver.IsValid(k8sVersion) {
-			return fmt.Errorf("k8s version detected '%s' is not a valid semantic version", k8sVersion)
-		}
-		n.k8sVersion = k8sVersion
-		n.rec.Log.Info("Kubernetes version detected", "version", k8sVersion)
-
-		promv1.AddToScheme(reconciler.Scheme)
-		secv1.Install(reconciler.Scheme)
-		apiconfigv1.Install(reconciler.Scheme)
-		apiimagev1.Install(reconciler.Scheme)
+	if n.operatorNamespace == "" {
+		return fmt.Errorf("the operator namespace is not set")
+	}
 
-		n.operatorMetrics = initOperatorMetrics(n)
-		n.rec.Log.Info("Operator metrics initialized.")
+	k8sVersion, err := reconciler.kubernetesVersion()
+	if err != nil {
+		return err
+	}
+	n.k8sVersion = k8sVersion
 
-		addState(n, "/opt/gpu-operator/pre-requisites")
-		// addState(n, "/opt/gpu-operator/state-driver")
-		addState(n, "/opt/gpu-operator/state-container-toolkit")
-		// addState(n, "/opt/gpu-operator/state-operator-validation")
-		addState(n, "/opt/gpu-operator/state-device-plugin")
-		// addState(n, "/opt/gpu-operator/gpu-feature-discovery")
-		// addState(n, "/opt/gpu-operator/state-node-status-exporter")
+	states, err := reconciler.stateAssets()
+	if err != nil {
+		return err
 	}
+	n.resources = states.resources
+	n.controls = states.controls
+	n.stateNames = states.stateNames
+	n.dependencies = states.dependencies
+
+	n.imageDigests = reconciler.cachedImageDigests()
+	n.resetImageDigests(clusterPolicy)
 
 	// 判断是否使用PSP
 	// retain PSP check for backward compatibility
@@ -824,10 +1076,39 @@ func (n *ClusterPolicyController) initOCPParams(ctx context.Context) error {
 }
 
 func (n *ClusterPolicyController) step() (gpuv1.State, error) {
+	result, err := n.reconcileState(n.idx)
+	if err != nil {
+		return result, err
+	}
+
+	// move to next state
+	n.idx = n.idx + 1
+
+	return result, nil
+}
+
+// reconcileState applies the controls of the state at idx. It works on a copy of the
+// controller, so that states can be reconciled concurrently
+func (n ClusterPolicyController) reconcileState(idx int) (gpuv1.State, error) {
+	n.idx = idx
 	result := gpuv1.Ready
-	klog.Infof("Start the state name: %v", n.stateNames[n.idx])
+	n.rec.Log.Info("Start the state", "name", n.stateNames[n.idx])
+
+	paused := n.isStatePaused(n.stateNames[n.idx])
+	if n.operatorMetrics != nil {
+		pausedValue := statePausedFalse
+		if paused {
+			pausedValue = statePausedTrue
+		}
+		n.operatorMetrics.statePaused.WithLabelValues(n.stateNames[n.idx]).Set(float64(pausedValue))
+	}
+	if paused {
+		// leave all resources of the state untouched
+		n.rec.Log.Info("Reconciliation of the state is paused", "name", n.stateNames[n.idx])
+		return gpuv1.Paused, nil
+	}
 	for _, fs := range n.controls[n.idx] {
-		stat, err := fs(*n)
+		stat, err := fs(n)
 		if err != nil {
 			return stat, err
 		}
@@ -838,14 +1119,76 @@ func (n *ClusterPolicyController) step() (gpuv1.State, error) {
 		}
 	}
 
-	// move to next state
-	n.idx = n.idx + 1
-
 	return result, nil
 }
 
-func (n ClusterPolicyController) validate() {
-	// TODO add custom validation functions
+// stateResult is the result of the reconciliation of a single state
+type stateResult struct {
+	name   string
+	status gpuv1.State
+	err    error
+	// blockedBy lists the dependencies which are not ready, if the state was skipped
+	blockedBy []string
+}
+
+// ready returns whether the states depending on this one can be reconciled
+func (s stateResult) ready() bool {
+	if s.err != nil {
+		return false
+	}
+	switch s.status {
+	case gpuv1.Ready, gpuv1.Disabled, gpuv1.Paused:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconcileStates reconciles every state as soon as the states it depends on are
+// reconciled, so that independent states are reconciled concurrently. A state is
+// skipped when one of its dependencies is not ready. The results are in the order
+// of the states
+func (n *ClusterPolicyController) reconcileStates() []stateResult {
+	results := make([]stateResult, len(n.controls))
+	done := make([]chan struct{}, len(n.controls))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i := range n.controls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			result := stateResult{name: n.stateNames[i]}
+			if i < len(n.dependencies) {
+				for _, dependency := range n.dependencies[i] {
+					<-done[dependency]
+					if !results[dependency].ready() {
+						result.blockedBy = append(result.blockedBy, n.stateNames[dependency])
+					}
+				}
+			}
+			if len(result.blockedBy) > 0 {
+				n.rec.Log.Info("Skipping the state, its dependencies are not ready", "name", result.name, "blockedBy", result.blockedBy)
+				result.status = gpuv1.Skipped
+			} else {
+				result.status, result.err = n.reconcileState(i)
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (n ClusterPolicyController) validate() error {
+	// the same checks are enforced by the admission webhook, repeat them
+	// here for clusters where the webhook is not deployed
+	return n.singleton.Validate()
 }
 
 func (n ClusterPolicyController) last() bool {
@@ -855,6 +1198,33 @@ func (n ClusterPolicyController) last() bool {
 	return false
 }
 
+// isStatePaused returns true if reconciliation of the state is paused,
+// either for the whole operator or for the component of the state
+func (n ClusterPolicyController) isStatePaused(stateName string) bool {
+	clusterPolicySpec := &n.singleton.Spec
+
+	if clusterPolicySpec.Operator.IsPaused() {
+		return true
+	}
+
+	switch stateName {
+	case "state-driver":
+		return clusterPolicySpec.Driver.IsPaused()
+	case "state-container-toolkit":
+		return clusterPolicySpec.Toolkit.IsPaused()
+	case "state-device-plugin":
+		return clusterPolicySpec.DevicePlugin.IsPaused()
+	case "gpu-feature-discovery":
+		return clusterPolicySpec.GPUFeatureDiscovery.IsPaused()
+	case "state-node-status-exporter":
+		return clusterPolicySpec.NodeStatusExporter.IsPaused()
+	case "state-operator-validation":
+		return clusterPolicySpec.Validator.IsPaused()
+	default:
+		return false
+	}
+}
+
 func (n ClusterPolicyController) isStateEnabled(stateName string) bool {
 	clusterPolicySpec := &n.singleton.Spec
 
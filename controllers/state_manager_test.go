@@ -1,9 +1,16 @@
 package controllers
 
 import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
 	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/NVIDIA/gpu-operator/assets"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
-	"testing"
 )
 
 func TestGetRuntimeString(t *testing.T) {
@@ -51,3 +58,218 @@ func TestGetRuntimeString(t *testing.T) {
 		})
 	}
 }
+
+func TestStepPausedState(t *testing.T) {
+	testCases := []struct {
+		description    string
+		operatorPaused *bool
+		pluginPaused   *bool
+		expectedState  gpuv1.State
+	}{
+		{"not paused", nil, nil, gpuv1.Ready},
+		{"component paused", nil, boolTrue, gpuv1.Paused},
+		{"operator paused", boolTrue, boolFalse, gpuv1.Paused},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cp := &gpuv1.ClusterPolicy{}
+			cp.Spec.Operator.Paused = tc.operatorPaused
+			cp.Spec.DevicePlugin.Paused = tc.pluginPaused
+
+			called := false
+			n := ClusterPolicyController{
+				rec:        &ClusterPolicyReconciler{Log: logr.Discard()},
+				singleton:  cp,
+				stateNames: []string{"state-device-plugin"},
+				controls: []controlFunc{{
+					func(n ClusterPolicyController) (gpuv1.State, error) {
+						called = true
+						return gpuv1.Ready, nil
+					},
+				}},
+			}
+
+			state, err := n.step()
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedState, state)
+			// resources of a paused state are left untouched
+			require.Equal(t, tc.expectedState != gpuv1.Paused, called)
+			require.True(t, n.last())
+		})
+	}
+}
+
+func TestReconcileStates(t *testing.T) {
+	control := func(state gpuv1.State, err error) controlFunc {
+		return controlFunc{func(n ClusterPolicyController) (gpuv1.State, error) {
+			return state, err
+		}}
+	}
+
+	testCases := []struct {
+		description string
+		toolkit     controlFunc
+		paused      *bool
+		expected    []gpuv1.State
+		blockedBy   []string
+	}{
+		{
+			description: "all states ready",
+			toolkit:     control(gpuv1.Ready, nil),
+			expected:    []gpuv1.State{gpuv1.Ready, gpuv1.Ready, gpuv1.Ready, gpuv1.Ready},
+		},
+		{
+			description: "dependency not ready",
+			toolkit:     control(gpuv1.NotReady, nil),
+			expected:    []gpuv1.State{gpuv1.Ready, gpuv1.NotReady, gpuv1.Skipped, gpuv1.Skipped},
+			blockedBy:   []string{"state-container-toolkit"},
+		},
+		{
+			description: "dependency failed",
+			toolkit:     control(gpuv1.NotReady, fmt.Errorf("failed")),
+			expected:    []gpuv1.State{gpuv1.Ready, gpuv1.NotReady, gpuv1.Skipped, gpuv1.Skipped},
+			blockedBy:   []string{"state-container-toolkit"},
+		},
+		{
+			description: "dependency disabled",
+			toolkit:     control(gpuv1.Disabled, nil),
+			expected:    []gpuv1.State{gpuv1.Ready, gpuv1.Disabled, gpuv1.Ready, gpuv1.Ready},
+		},
+		{
+			description: "dependency paused",
+			toolkit:     control(gpuv1.NotReady, nil),
+			paused:      boolTrue,
+			expected:    []gpuv1.State{gpuv1.Ready, gpuv1.Paused, gpuv1.Ready, gpuv1.Ready},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cp := &gpuv1.ClusterPolicy{}
+			cp.Spec.Toolkit.Paused = tc.paused
+
+			n := ClusterPolicyController{
+				rec:        &ClusterPolicyReconciler{Log: logr.Discard()},
+				singleton:  cp,
+				stateNames: []string{"pre-requisites", "state-container-toolkit", "state-device-plugin", "gpu-feature-discovery"},
+				controls: []controlFunc{
+					control(gpuv1.Ready, nil),
+					tc.toolkit,
+					control(gpuv1.Ready, nil),
+					control(gpuv1.Ready, nil),
+				},
+				dependencies: [][]int{nil, {0}, {1}, {1}},
+			}
+
+			results := n.reconcileStates()
+			require.Len(t, results, len(tc.expected))
+			for i, result := range results {
+				require.Equal(t, n.stateNames[i], result.name)
+				ss := result.stateStatus()
+				if result.err != nil {
+					require.Equal(t, gpuv1.NotReady, ss.State)
+				}
+				require.Equal(t, tc.expected[i], ss.State, result.name)
+				if ss.State == gpuv1.Skipped {
+					require.Equal(t, tc.blockedBy, result.blockedBy)
+					require.Contains(t, ss.Message, strings.Join(tc.blockedBy, ", "))
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileStatesConcurrently(t *testing.T) {
+	// the two states only complete if they run concurrently
+	started := []chan struct{}{make(chan struct{}), make(chan struct{})}
+	waitForOther := func(self, other int) controlFunc {
+		return controlFunc{func(n ClusterPolicyController) (gpuv1.State, error) {
+			close(started[self])
+			select {
+			case <-started[other]:
+				return gpuv1.Ready, nil
+			case <-time.After(5 * time.Second):
+				return gpuv1.NotReady, nil
+			}
+		}}
+	}
+
+	n := ClusterPolicyController{
+		rec:          &ClusterPolicyReconciler{Log: logr.Discard()},
+		singleton:    &gpuv1.ClusterPolicy{},
+		stateNames:   []string{"state-device-plugin", "gpu-feature-discovery"},
+		controls:     []controlFunc{waitForOther(0, 1), waitForOther(1, 0)},
+		dependencies: [][]int{nil, nil},
+	}
+
+	for _, result := range n.reconcileStates() {
+		require.NoError(t, result.err)
+		require.Equal(t, gpuv1.Ready, result.status, result.name)
+	}
+}
+
+func TestStateDependencies(t *testing.T) {
+	// dependencies are added before the states depending on them
+	for i, state := range operandStates {
+		for _, dependency := range stateDependencies[state] {
+			for _, later := range operandStates[i:] {
+				require.NotEqual(t, dependency, later, "%s must be listed after its dependency %s", state, dependency)
+			}
+		}
+	}
+
+	n := &ClusterPolicyController{}
+	err := addState(n, assets.FS, "state-container-toolkit", "state-device-plugin")
+	require.Error(t, err)
+	require.Empty(t, n.stateNames)
+}
+
+func TestLoadStateAssets(t *testing.T) {
+	testCases := []struct {
+		description   string
+		states        []string
+		expected      []string
+		errorExpected bool
+	}{
+		{
+			description: "default states",
+			states:      defaultOperandStates,
+			expected:    []string{"pre-requisites", "state-container-toolkit", "state-device-plugin"},
+		},
+		{
+			description: "states are added in order",
+			states:      []string{"gpu-feature-discovery", "state-container-toolkit", "pre-requisites"},
+			expected:    []string{"pre-requisites", "state-container-toolkit", "gpu-feature-discovery"},
+		},
+		{
+			description:   "unknown state",
+			states:        []string{"pre-requisites", "state-foo"},
+			errorExpected: true,
+		},
+		{
+			description:   "duplicate state",
+			states:        []string{"pre-requisites", "pre-requisites"},
+			errorExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &ClusterPolicyReconciler{Log: logr.Discard()}
+			states, err := loadStateAssets(r, assets.FS, tc.states)
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, states.stateNames)
+			require.Len(t, states.controls, len(tc.expected))
+			for i, state := range states.stateNames {
+				for _, dependency := range states.dependencies[i] {
+					require.Less(t, dependency, i, "%s depends on a state added after it", state)
+				}
+			}
+		})
+	}
+}
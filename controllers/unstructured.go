@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// assetNamespacePlaceholder is the namespace of the namespaced objects in the assets,
+// replaced by the operator namespace
+const assetNamespacePlaceholder = "FILLED BY THE OPERATOR"
+
+// ReadinessCheck returns whether an object applied from the assets of a state is
+// ready, given the object as returned by the API server. An error means the object
+// cannot become ready, such as a failed Job
+type ReadinessCheck func(obj *unstructured.Unstructured) (bool, error)
+
+var (
+	readinessChecksMu sync.RWMutex
+	// readinessChecks maps the kinds without a typed control to their readiness check,
+	// kinds without a check are ready when their Ready or Available condition is True
+	readinessChecks = map[schema.GroupVersionKind]ReadinessCheck{
+		{Group: "batch", Version: "v1", Kind: "Job"}:        isJobReady,
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"}: isStatefulSetReady,
+	}
+)
+
+// RegisterReadinessCheck sets the readiness check of the objects of the given kind
+// applied from the state assets, replacing the previous check of the kind if any.
+// It has no effect on the kinds which have a typed control, such as DaemonSet
+func RegisterReadinessCheck(gvk schema.GroupVersionKind, check ReadinessCheck) {
+	readinessChecksMu.Lock()
+	defer readinessChecksMu.Unlock()
+	readinessChecks[gvk] = check
+}
+
+func readinessCheck(gvk schema.GroupVersionKind) ReadinessCheck {
+	readinessChecksMu.RLock()
+	defer readinessChecksMu.RUnlock()
+	if check, ok := readinessChecks[gvk]; ok {
+		return check
+	}
+	return isConditionReady
+}
+
+// decodeUnstructured decodes a manifest of a kind without a typed control
+func decodeUnstructured(m assetsFromFile) (unstructured.Unstructured, error) {
+	obj := unstructured.Unstructured{}
+	err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(m), len(m)).Decode(&obj.Object)
+	if err != nil {
+		return obj, err
+	}
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return obj, fmt.Errorf("manifest has no apiVersion or kind")
+	}
+	if obj.GetName() == "" {
+		return obj, fmt.Errorf("%s has no name", obj.GetKind())
+	}
+	return obj, nil
+}
+
+// Objects creates or updates the objects of the kinds without a typed control. The
+// ClusterRole of the operator only grants access to some of them, such as Jobs and
+// PodDisruptionBudgets, other kinds need an additional role
+func Objects(n ClusterPolicyController) (gpuv1.State, error) {
+	status := gpuv1.Ready
+	state := n.idx
+	for i := range n.resources[state].Objects {
+		stat, err := applyObject(n, i)
+		if err != nil {
+			return stat, err
+		}
+		if stat != gpuv1.Ready {
+			status = stat
+		}
+	}
+	return status, nil
+}
+
+func applyObject(n ClusterPolicyController, idx int) (gpuv1.State, error) {
+	ctx := n.ctx
+	state := n.idx
+	obj := n.resources[state].Objects[idx].DeepCopy()
+	if obj.GetNamespace() == assetNamespacePlaceholder {
+		obj.SetNamespace(n.operatorNamespace)
+	}
+
+	logger := n.rec.Log.WithValues(obj.GetKind(), obj.GetName(), "Namespace", obj.GetNamespace())
+
+	// Check if state is disabled and cleanup resource if exists
+	if !n.isStateEnabled(n.stateNames[n.idx]) {
+		err := n.rec.Client.Delete(ctx, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Info("Couldn't delete", "Error", err)
+			return gpuv1.NotReady, err
+		}
+		return gpuv1.Disabled, nil
+	}
+
+	if err := controllerutil.SetControllerReference(n.singleton, obj, n.rec.Scheme); err != nil {
+		return gpuv1.NotReady, err
+	}
+
+	if err := serverSideApply(n, obj); err != nil {
+		logger.Info("Couldn't apply", "Error", err)
+		return gpuv1.NotReady, err
+	}
+	return objectReadiness(obj)
+}
+
+func objectReadiness(obj *unstructured.Unstructured) (gpuv1.State, error) {
+	ready, err := readinessCheck(obj.GroupVersionKind())(obj)
+	if err != nil {
+		return gpuv1.NotReady, fmt.Errorf("%s %s is not ready: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	if !ready {
+		return gpuv1.NotReady, nil
+	}
+	return gpuv1.Ready, nil
+}
+
+// conditionStatus returns the status of the condition of the given type, or an
+// empty string if obj has no such condition
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		return status
+	}
+	return ""
+}
+
+// isConditionReady is the readiness check of the kinds without a registered check:
+// objects with a Ready or Available condition are ready when it is True, the others
+// once they exist
+func isConditionReady(obj *unstructured.Unstructured) (bool, error) {
+	for _, conditionType := range []string{"Ready", "Available"} {
+		if status := conditionStatus(obj, conditionType); status != "" {
+			return status == "True", nil
+		}
+	}
+	return true, nil
+}
+
+func isJobReady(obj *unstructured.Unstructured) (bool, error) {
+	if conditionStatus(obj, "Failed") == "True" {
+		return false, fmt.Errorf("the Job failed")
+	}
+	return conditionStatus(obj, "Complete") == "True", nil
+}
+
+func isStatefulSetReady(obj *unstructured.Unstructured) (bool, error) {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	readyReplicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	updatedReplicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	return observedGeneration >= obj.GetGeneration() && readyReplicas >= replicas && updatedReplicas >= replicas, nil
+}
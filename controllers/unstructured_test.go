@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	gpuv1 "github.com/NVIDIA/gpu-operator/api/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	unstructuredPDB = `apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: device-plugin-pdb
+  namespace: "FILLED BY THE OPERATOR"
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      app: xdxct-device-plugin-daemonset
+`
+	unstructuredJob = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: device-plugin-setup
+  namespace: "FILLED BY THE OPERATOR"
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: setup
+        image: busybox
+`
+)
+
+func TestObjects(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewRenderScheme()
+	require.NoError(t, err)
+	c := &applyClient{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+
+	cp := &gpuv1.ClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "cluster-policy", UID: "cluster-policy-uid"}}
+	n := ClusterPolicyController{
+		ctx:               ctx,
+		singleton:         cp,
+		operatorNamespace: "gpu-operator",
+		rec:               &ClusterPolicyReconciler{Client: c, Log: logr.Discard(), Scheme: s},
+	}
+	assets := fstest.MapFS{
+		"state-device-plugin/0100_pdb.yaml": {Data: []byte(unstructuredPDB)},
+		"state-device-plugin/0200_job.yaml": {Data: []byte(unstructuredJob)},
+	}
+	res, ctrl, err := addResourcesControls(&n, assets, "state-device-plugin")
+	require.NoError(t, err)
+	require.Len(t, res.Objects, 2)
+	require.Len(t, ctrl, 1)
+	n.resources = []Resources{res}
+	n.controls = []controlFunc{ctrl}
+	n.stateNames = []string{"state-device-plugin"}
+
+	// the Job is not complete yet
+	status, err := Objects(n)
+	require.NoError(t, err)
+	require.Equal(t, gpuv1.NotReady, status)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "gpu-operator", Name: "device-plugin-pdb"}, pdb)
+	require.NoError(t, err)
+	require.Equal(t, 1, pdb.Spec.MaxUnavailable.IntValue())
+	require.Len(t, pdb.OwnerReferences, 1)
+	require.Equal(t, cp.UID, pdb.OwnerReferences[0].UID)
+
+	job := &batchv1.Job{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "gpu-operator", Name: "device-plugin-setup"}, job)
+	require.NoError(t, err)
+	// fields set by the API server are kept on update
+	job.Spec.Template.Labels = map[string]string{"controller-uid": "job-uid"}
+	require.NoError(t, c.Update(ctx, job))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	require.NoError(t, c.Status().Update(ctx, job))
+
+	status, err = Objects(n)
+	require.NoError(t, err)
+	require.Equal(t, gpuv1.Ready, status)
+	err = c.Get(ctx, types.NamespacedName{Namespace: "gpu-operator", Name: "device-plugin-setup"}, job)
+	require.NoError(t, err)
+	require.Equal(t, "job-uid", job.Spec.Template.Labels["controller-uid"])
+
+	// the objects are deleted with their state
+	disabled := false
+	cp.Spec.DevicePlugin.Enabled = &disabled
+	status, err = Objects(n)
+	require.NoError(t, err)
+	require.Equal(t, gpuv1.Disabled, status)
+	err = c.Get(ctx, types.NamespacedName{Namespace: "gpu-operator", Name: "device-plugin-pdb"}, pdb)
+	require.True(t, errors.IsNotFound(err))
+	err = c.Get(ctx, types.NamespacedName{Namespace: "gpu-operator", Name: "device-plugin-setup"}, job)
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestReadinessChecks(t *testing.T) {
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	RegisterReadinessCheck(widget, func(obj *unstructured.Unstructured) (bool, error) {
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "Running", nil
+	})
+
+	testCases := []struct {
+		description   string
+		obj           map[string]interface{}
+		ready         bool
+		errorExpected bool
+	}{
+		{
+			description: "no conditions",
+			obj:         map[string]interface{}{"apiVersion": "policy/v1", "kind": "PodDisruptionBudget"},
+			ready:       true,
+		},
+		{
+			description: "ready condition false",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Gadget",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "False"},
+				}},
+			},
+		},
+		{
+			description: "available condition true",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Gadget",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True"},
+				}},
+			},
+			ready: true,
+		},
+		{
+			description: "job complete",
+			obj: map[string]interface{}{"apiVersion": "batch/v1", "kind": "Job",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Complete", "status": "True"},
+				}},
+			},
+			ready: true,
+		},
+		{
+			description: "job failed",
+			obj: map[string]interface{}{"apiVersion": "batch/v1", "kind": "Job",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Failed", "status": "True"},
+				}},
+			},
+			errorExpected: true,
+		},
+		{
+			description: "statefulset rolling out",
+			obj: map[string]interface{}{"apiVersion": "apps/v1", "kind": "StatefulSet",
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"readyReplicas": int64(2), "updatedReplicas": int64(1)},
+			},
+		},
+		{
+			description: "statefulset ready",
+			obj: map[string]interface{}{"apiVersion": "apps/v1", "kind": "StatefulSet",
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"readyReplicas": int64(2), "updatedReplicas": int64(2)},
+			},
+			ready: true,
+		},
+		{
+			description: "registered check",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget",
+				"status": map[string]interface{}{"phase": "Pending"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tc.obj}
+			status, err := objectReadiness(obj)
+			if tc.errorExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			expected := gpuv1.NotReady
+			if tc.ready {
+				expected = gpuv1.Ready
+			}
+			require.Equal(t, expected, status)
+		})
+	}
+}
@@ -47,6 +47,10 @@ type UpgradeReconciler struct {
 	Log          logr.Logger
 	Scheme       *runtime.Scheme
 	StateManager *upgrade.ClusterUpgradeStateManager
+	// OperatorNamespace is the namespace the driver is deployed to
+	OperatorNamespace string
+	// Metrics are updated by every reconciliation, nothing is reported if nil
+	Metrics *OperatorMetrics
 }
 
 const (
@@ -77,8 +81,8 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	err := r.Client.Get(context.TODO(), req.NamespacedName, clusterPolicy)
 	if err != nil {
 		reqLogger.V(consts.LogLevelError).Error(err, "Error getting ClusterPolicy object")
-		if clusterPolicyCtrl.operatorMetrics != nil {
-			clusterPolicyCtrl.operatorMetrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
+		if r.Metrics != nil {
+			r.Metrics.reconciliationStatus.Set(reconciliationStatusClusterPolicyUnavailable)
 		}
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -94,19 +98,19 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		!clusterPolicy.Spec.Driver.UpgradePolicy.AutoUpgrade {
 		reqLogger.V(consts.LogLevelInfo).Info("Advanced driver upgrade policy is disabled, cleaning up upgrade state and skipping reconciliation")
 		// disable driver upgrade metrics
-		if clusterPolicyCtrl.operatorMetrics != nil {
-			clusterPolicyCtrl.operatorMetrics.driverAutoUpgradeEnabled.Set(driverAutoUpgradeDisabled)
+		if r.Metrics != nil {
+			r.Metrics.driverAutoUpgradeEnabled.Set(driverAutoUpgradeDisabled)
 		}
 		return ctrl.Result{}, r.removeNodeUpgradeStateLabels(ctx)
 	}
 	// enable driver upgrade metrics
-	if clusterPolicyCtrl.operatorMetrics != nil {
-		clusterPolicyCtrl.operatorMetrics.driverAutoUpgradeEnabled.Set(driverAutoUpgradeEnabled)
+	if r.Metrics != nil {
+		r.Metrics.driverAutoUpgradeEnabled.Set(driverAutoUpgradeEnabled)
 	}
 
 	driverLabelKey := DriverLabelKey
 	driverLabelValue := DriverLabelValue
-	state, err := r.StateManager.BuildState(ctx, clusterPolicyCtrl.operatorNamespace, map[string]string{driverLabelKey: driverLabelValue})
+	state, err := r.StateManager.BuildState(ctx, r.OperatorNamespace, map[string]string{driverLabelKey: driverLabelValue})
 	if err != nil {
 		r.Log.Error(err, "Failed to build cluster upgrade state")
 		return ctrl.Result{}, err
@@ -126,12 +130,12 @@ func (r *UpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	// log metrics with the current state
-	if clusterPolicyCtrl.operatorMetrics != nil {
-		clusterPolicyCtrl.operatorMetrics.upgradesInProgress.Set(float64(r.StateManager.GetUpgradesInProgress(ctx, state)))
-		clusterPolicyCtrl.operatorMetrics.upgradesDone.Set(float64(r.StateManager.GetUpgradesDone(ctx, state)))
-		clusterPolicyCtrl.operatorMetrics.upgradesAvailable.Set(float64(r.StateManager.GetUpgradesAvailable(ctx, state, clusterPolicy.Spec.Driver.UpgradePolicy.MaxParallelUpgrades, maxUnavailable)))
-		clusterPolicyCtrl.operatorMetrics.upgradesFailed.Set(float64(r.StateManager.GetUpgradesFailed(ctx, state)))
-		clusterPolicyCtrl.operatorMetrics.upgradesPending.Set(float64(r.StateManager.GetUpgradesPending(ctx, state)))
+	if r.Metrics != nil {
+		r.Metrics.upgradesInProgress.Set(float64(r.StateManager.GetUpgradesInProgress(ctx, state)))
+		r.Metrics.upgradesDone.Set(float64(r.StateManager.GetUpgradesDone(ctx, state)))
+		r.Metrics.upgradesAvailable.Set(float64(r.StateManager.GetUpgradesAvailable(ctx, state, clusterPolicy.Spec.Driver.UpgradePolicy.MaxParallelUpgrades, maxUnavailable)))
+		r.Metrics.upgradesFailed.Set(float64(r.StateManager.GetUpgradesFailed(ctx, state)))
+		r.Metrics.upgradesPending.Set(float64(r.StateManager.GetUpgradesPending(ctx, state)))
 	}
 
 	err = r.StateManager.ApplyState(ctx, state, clusterPolicy.Spec.Driver.UpgradePolicy)
@@ -0,0 +1,38 @@
+/**
+# Copyright (c), NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+// Package gpuoperator embeds the files of the Helm chart read by gpuop-cfg, so that
+// it does not depend on a checkout of the repository
+package gpuoperator
+
+import (
+	_ "embed"
+)
+
+// ClusterPolicyCRD is the ClusterPolicy CRD installed by the chart
+//
+//go:embed crds/xdxct.com_clusterpolicies_crd.yaml
+var ClusterPolicyCRD []byte
+
+// Values are the default values of the chart
+//
+//go:embed values.yaml
+var Values []byte
+
+// Chart is the Chart.yaml of the chart, holding its appVersion
+//
+//go:embed Chart.yaml
+var Chart []byte
@@ -37,6 +37,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	apiconfigv1 "github.com/openshift/api/config/v1"
+	apiimagev1 "github.com/openshift/api/image/v1"
+	secv1 "github.com/openshift/api/security/v1"
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
 	clusterpolicyv1 "github.com/NVIDIA/gpu-operator/api/v1"
 	"github.com/NVIDIA/gpu-operator/controllers"
 	// +kubebuilder:scaffold:imports
@@ -52,6 +57,10 @@ func init() {
 
 	utilruntime.Must(clusterpolicyv1.AddToScheme(scheme))
 	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(promv1.AddToScheme(scheme))
+	utilruntime.Must(secv1.Install(scheme))
+	utilruntime.Must(apiconfigv1.Install(scheme))
+	utilruntime.Must(apiimagev1.Install(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -60,6 +69,9 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var renewDeadline time.Duration
+	var enableWebhooks bool
+	var assetsOverlay string
+	var states string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -71,6 +83,14 @@ func main() {
 			"Only enabled when the --leader-elect flag is set. "+
 			"If undefined, the renew deadline defaults to the controller-runtime manager's default RenewDeadline. "+
 			"By setting this option, the LeaseDuration is also set as RenewDealine + 5s.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the ClusterPolicy defaulting and validating admission webhooks. "+
+			"Requires serving certificates to be mounted in the webhook server cert directory.")
+	flag.StringVar(&assetsOverlay, "assets-overlay", "",
+		"The directory holding manifests which replace or are added to the assets embedded in the operator. "+
+			"It holds a directory per state, e.g. state-device-plugin/0400_daemonset.yaml replaces the DaemonSet of the device plugin.")
+	flag.StringVar(&states, "states", strings.Join(controllers.DefaultOperandStates(), ","),
+		"The comma-separated list of the states deployed by the operator.")
 
 	opts := zap.Options{
 		StacktraceLevel: zapcore.PanicLevel,
@@ -105,16 +125,54 @@ func main() {
 		os.Exit(1)
 	}
 
+	operatorNamespace := os.Getenv("OPERATOR_NAMESPACE")
+	if operatorNamespace == "" {
+		// we cannot do anything without the operator namespace,
+		// let the operator Pod run into `CrashloopBackOff`
+		setupLog.Error(nil, "OPERATOR_NAMESPACE environment variable not set, cannot proceed")
+		os.Exit(1)
+	}
+
+	assets, err := controllers.AssetsFS(assetsOverlay)
+	if err != nil {
+		setupLog.Error(err, "unable to load the assets")
+		os.Exit(1)
+	}
+
+	operandStates, err := controllers.ParseOperandStates(states)
+	if err != nil {
+		setupLog.Error(err, "invalid --states")
+		os.Exit(1)
+	}
+
+	operatorMetrics, err := controllers.NewOperatorMetrics(nil)
+	if err != nil {
+		setupLog.Error(err, "unable to initialize operator metrics")
+		os.Exit(1)
+	}
+
 	ctx := ctrl.SetupSignalHandler()
 	if err = (&controllers.ClusterPolicyReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ClusterPolicy"),
-		Scheme: mgr.GetScheme(),
+		Client:            mgr.GetClient(),
+		APIReader:         mgr.GetAPIReader(),
+		Log:               ctrl.Log.WithName("controllers").WithName("ClusterPolicy"),
+		Scheme:            mgr.GetScheme(),
+		OperatorNamespace: operatorNamespace,
+		Metrics:           operatorMetrics,
+		Assets:            assets,
+		States:            operandStates,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterPolicy")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = (&clusterpolicyv1.ClusterPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterPolicy")
+			os.Exit(1)
+		}
+	}
+
 	// setup upgrade controller
 	// upgrade.SetDriverName("gpu")
 	// upgradeLogger := ctrl.Log.WithName("controllers").WithName("Upgrade")
@@ -130,10 +188,12 @@ func main() {
 	// clusterUpgradeStateManager = clusterUpgradeStateManager.WithPodDeletionEnabled(gpuPodSpecFilter).WithValidationEnabled("app=nvidia-operator-validator")
 
 	// if err = (&controllers.UpgradeReconciler{
-	// 	Client:       mgr.GetClient(),
-	// 	Log:          upgradeLogger,
-	// 	Scheme:       mgr.GetScheme(),
-	// 	StateManager: clusterUpgradeStateManager,
+	// 	Client:            mgr.GetClient(),
+	// 	Log:               upgradeLogger,
+	// 	Scheme:            mgr.GetScheme(),
+	// 	StateManager:      clusterUpgradeStateManager,
+	// 	OperatorNamespace: operatorNamespace,
+	// 	Metrics:           operatorMetrics,
 	// }).SetupWithManager(mgr); err != nil {
 	// 	setupLog.Error(err, "unable to create controller", "controller", "Upgrade")
 	// 	os.Exit(1)